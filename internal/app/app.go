@@ -11,23 +11,27 @@ import (
 
 	"calc_example/internal/config"
 	"calc_example/internal/handler"
+	"calc_example/internal/notifier"
 	"calc_example/internal/repository"
 	"calc_example/internal/service"
 	"calc_example/pkg/database"
 	"calc_example/pkg/logger"
+	"calc_example/pkg/storage"
+	"calc_example/pkg/telegram"
 
 	"github.com/gin-gonic/gin"
 )
 
 type App struct {
-	config  *config.Config
-	logger  *logger.Logger
-	router  *gin.Engine
-	server  *http.Server
-	db      *database.Database
-	handler *handler.Handler
-	service *service.Service
-	repo    *repository.Repository
+	config   *config.Config
+	logger   *logger.Logger
+	router   *gin.Engine
+	server   *http.Server
+	db       *database.Database
+	handler  *handler.Handler
+	service  *service.Service
+	notifier *notifier.Notifier
+	repo     *repository.Repository
 }
 
 func New(cfg *config.Config) *App {
@@ -43,11 +47,34 @@ func New(cfg *config.Config) *App {
 	// Инициализируем репозиторий
 	repo := repository.New(db)
 
+	// Инициализируем хранилище вложений
+	files, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal("Ошибка подключения к хранилищу файлов:", err)
+	}
+
 	// Инициализируем сервисы
-	services := service.New(repo)
+	services := service.New(repo, files, cfg)
+
+	// Связываем с клиентами заявки, созданные до появления карточек клиентов
+	if linked, err := services.LinkCustomers(); err != nil {
+		log.Error("Ошибка связывания заявок с клиентами:", err)
+	} else if linked > 0 {
+		log.Info("Заявки связаны с клиентами: ", linked)
+	}
+
+	// Разбираем ссылки на товары в заявках, созданных до появления разбора ссылок
+	if linked, err := services.LinkProducts(); err != nil {
+		log.Error("Ошибка разбора ссылок на товары:", err)
+	} else if linked > 0 {
+		log.Info("Разобраны ссылки на товары в заявках: ", linked)
+	}
+
+	// Инициализируем уведомления в Telegram
+	notifications := notifier.New(telegram.New(cfg.TelegramBot.Url), cfg.Frontend, cfg.Server.PublicURL)
 
 	// Инициализируем хендлеры
-	handlers := handler.New(services, log)
+	handlers := handler.New(services, notifications, log)
 
 	// Инициализируем роутер
 	router := gin.Default()
@@ -65,18 +92,25 @@ func New(cfg *config.Config) *App {
 	}
 
 	return &App{
-		config:  cfg,
-		logger:  log,
-		router:  router,
-		server:  server,
-		db:      db,
-		handler: handlers,
-		service: services,
-		repo:    repo,
+		config:   cfg,
+		logger:   log,
+		router:   router,
+		server:   server,
+		db:       db,
+		handler:  handlers,
+		service:  services,
+		notifier: notifications,
+		repo:     repo,
 	}
 }
 
 func (a *App) Run() error {
+	// Запускаем фоновые задачи
+	ctx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go a.runPeriodic(ctx, a.config.Reminder.CheckInterval, a.fireDueReminders)
+	go a.runPeriodic(ctx, a.config.SLA.CheckInterval, a.escalateSLA)
+
 	// Запускаем сервер в горутине
 	go func() {
 		a.logger.Info("Сервер запущен на: ", a.config.Server.Host, ":", a.config.Server.Port)
@@ -91,6 +125,7 @@ func (a *App) Run() error {
 	<-quit
 
 	a.logger.Info("Получен сигнал завершения, закрываем сервер...")
+	stopScheduler()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -120,7 +155,8 @@ func setupMiddleware(router *gin.Engine, log *logger.Logger) {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag, Content-Disposition, X-Total-Count, Link")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
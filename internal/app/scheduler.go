@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"calc_example/internal/service"
+)
+
+// runPeriodic выполняет job сразу и затем с интервалом interval,
+// пока не будет отменен контекст
+func (a *App) runPeriodic(ctx context.Context, interval time.Duration, job func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fireDueReminders отправляет наступившие напоминания. Напоминания хранятся
+// в базе данных, поэтому пропущенные во время простоя отправляются после перезапуска.
+func (a *App) fireDueReminders() {
+	reminders, err := a.service.GetDueReminders(time.Now())
+	if err != nil {
+		a.logger.Error("Ошибка получения напоминаний:", err)
+		return
+	}
+
+	for i := range reminders {
+		reminder := &reminders[i]
+
+		issue, err := a.service.GetIssueByID(reminder.IssueID)
+		if errors.Is(err, service.ErrIssueNotFound) {
+			// Заявка удалена - напоминание больше не актуально
+			a.logger.Warn("Напоминание для удаленной заявки:", reminder.IssueID)
+		} else if err != nil {
+			a.logger.Error("Ошибка получения заявки:", err)
+			continue
+		} else if err := a.notifier.Reminder(issue, reminder); err != nil {
+			// Повторим попытку на следующем тике
+			a.logger.Error("Ошибка отправки напоминания в Telegram:", err)
+			continue
+		}
+
+		if err := a.service.CompleteReminder(reminder.ID); err != nil {
+			a.logger.Error("Ошибка завершения напоминания:", err)
+		}
+	}
+}
+
+// escalateSLA предупреждает менеджеров о заявках, по которым вот-вот
+// будет нарушен SLA. Эскалация по каждому показателю отправляется один раз.
+func (a *App) escalateSLA() {
+	escalations, err := a.service.GetSLAEscalations(time.Now())
+	if err != nil {
+		a.logger.Error("Ошибка проверки SLA:", err)
+		return
+	}
+
+	for _, escalation := range escalations {
+		if err := a.notifier.SLAEscalation(escalation.Issue, escalation.Metric); err != nil {
+			a.logger.Error("Ошибка отправки эскалации в Telegram:", err)
+			continue
+		}
+
+		if err := a.service.MarkSLAEscalated(escalation.Issue.ID, escalation.Metric); err != nil {
+			a.logger.Error("Ошибка сохранения эскалации:", err)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 )
@@ -12,12 +16,20 @@ type Config struct {
 	TelegramBot TelegramBotConfig
 	Frontend    FrontendConfig
 	Database    DatabaseConfig
+	Reminder    ReminderConfig
+	SLA         SLAConfig
+	Duplicate   DuplicateConfig
+	Scoring     ScoringConfig
+	Storage     StorageConfig
+	Upload      UploadConfig
 	Log         LogConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Host string
+	// Внешний адрес API для ссылок на файлы в оповещениях
+	PublicURL string
 }
 
 type TelegramBotConfig struct {
@@ -39,6 +51,84 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+type ReminderConfig struct {
+	// Интервал проверки наступивших напоминаний
+	CheckInterval time.Duration
+}
+
+// SLAConfig описывает целевые сроки обработки заявок и рабочий календарь
+type SLAConfig struct {
+	// Целевое время до первого контакта с клиентом (в рабочем времени)
+	FirstContactTarget time.Duration
+	// Целевое время до отправки расчета (в рабочем времени)
+	QuoteTarget time.Duration
+	// За сколько до нарушения отправлять эскалацию
+	WarnBefore time.Duration
+	// Начало и конец рабочего дня как смещение от полуночи
+	WorkdayStart time.Duration
+	WorkdayEnd   time.Duration
+	WorkDays     []time.Weekday
+	// Праздничные дни в формате YYYY-MM-DD
+	Holidays      []string
+	Location      *time.Location
+	CheckInterval time.Duration
+}
+
+// DuplicateConfig описывает поиск повторных заявок от одного клиента
+type DuplicateConfig struct {
+	// Период, в котором ищутся предыдущие заявки
+	Window time.Duration
+	// Минимальная похожесть имени и товара (от 0 до 1)
+	Similarity float64
+	// Сколько последних заявок сравнивается по имени и товару
+	Candidates int
+}
+
+// ScoringConfig задает веса правил оценки заявок
+type ScoringConfig struct {
+	ChinaExperience  int
+	SupplierContacts int
+	Volume           int
+	Weight           int
+	PreviousInvoice  int
+	// Баллы за срок доставки не дальше DeadlineSoonDays дней
+	DeadlineSoon     int
+	DeadlineSoonDays int
+	// Баллы за срок доставки, который уже прошел
+	DeadlinePassed int
+	// Баллы по источникам заявки
+	Sources map[string]int
+}
+
+// StorageConfig задает хранилище вложений: локальный каталог (local)
+// или S3-совместимое хранилище, например MinIO (s3)
+type StorageConfig struct {
+	Driver    string
+	LocalPath string
+	S3        S3Config
+	// Срок действия временных ссылок на скачивание
+	PresignExpiry time.Duration
+}
+
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// UploadConfig ограничивает загружаемые файлы
+type UploadConfig struct {
+	MaxFileSize  int64
+	MaxIssueSize int64
+	// Допустимые типы содержимого, определяемые по самому файлу
+	AllowedTypes []string
+	// Сторона квадрата, в который вписываются превью изображений
+	ThumbnailSize int
+}
+
 type LogConfig struct {
 	Level string
 }
@@ -58,8 +148,8 @@ func Load() (*Config, error) {
 			Url: getEnv("TELEGRAM_BOT_SERVICE", ""),
 		},
 		Frontend: FrontendConfig{
-			Url:  getEnv("FRONTEND_HOST", ""),
-			Port: getEnv("FRONTEND_PORT", ""),
+			Url:  getEnv("FRONTEND_HOST", "http://127.0.0.1"),
+			Port: getEnv("FRONTEND_PORT", "8081"),
 		},
 		Database: DatabaseConfig{
 			Driver:   getEnv("DB_DRIVER", "sqlite"),
@@ -70,14 +160,128 @@ func Load() (*Config, error) {
 			DBName:   getEnv("DB_NAME", "calc_example"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
+		SLA: SLAConfig{
+			FirstContactTarget: time.Duration(getEnvAsInt("SLA_FIRST_CONTACT_MINUTES", 15)) * time.Minute,
+			QuoteTarget:        time.Duration(getEnvAsInt("SLA_QUOTE_MINUTES", 480)) * time.Minute,
+			WarnBefore:         time.Duration(getEnvAsInt("SLA_WARN_BEFORE_MINUTES", 5)) * time.Minute,
+		},
+		Duplicate: DuplicateConfig{
+			Window:     time.Duration(getEnvAsInt("DUPLICATE_WINDOW_HOURS", 72)) * time.Hour,
+			Similarity: getEnvAsFloat("DUPLICATE_SIMILARITY", 0.85),
+			Candidates: getEnvAsInt("DUPLICATE_CANDIDATES", 200),
+		},
+		Scoring: ScoringConfig{
+			ChinaExperience:  getEnvAsInt("SCORE_CHINA_EXPERIENCE", 15),
+			SupplierContacts: getEnvAsInt("SCORE_SUPPLIER_CONTACTS", 20),
+			Volume:           getEnvAsInt("SCORE_VOLUME", 10),
+			Weight:           getEnvAsInt("SCORE_WEIGHT", 10),
+			PreviousInvoice:  getEnvAsInt("SCORE_PREVIOUS_INVOICE", 15),
+			DeadlineSoon:     getEnvAsInt("SCORE_DEADLINE_SOON", 10),
+			DeadlineSoonDays: getEnvAsInt("SCORE_DEADLINE_SOON_DAYS", 60),
+			DeadlinePassed:   getEnvAsInt("SCORE_DEADLINE_PASSED", -10),
+		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			LocalPath: getEnv("STORAGE_LOCAL_PATH", "./data/attachments"),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "localhost:9000"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				Bucket:    getEnv("S3_BUCKET", "attachments"),
+				Region:    getEnv("S3_REGION", ""),
+				UseSSL:    getEnvAsBool("S3_USE_SSL", false),
+			},
+			PresignExpiry: time.Duration(getEnvAsInt("STORAGE_PRESIGN_EXPIRY_MINUTES", 15)) * time.Minute,
+		},
+		Upload: UploadConfig{
+			MaxFileSize:  int64(getEnvAsInt("UPLOAD_MAX_FILE_MB", 10)) << 20,
+			MaxIssueSize: int64(getEnvAsInt("UPLOAD_MAX_ISSUE_MB", 50)) << 20,
+			AllowedTypes: getEnvAsList("UPLOAD_ALLOWED_TYPES", strings.Join([]string{
+				"application/pdf",
+				"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+				"text/csv",
+				"image/jpeg",
+				"image/png",
+				"image/webp",
+				"image/heic",
+			}, ",")),
+			ThumbnailSize: getEnvAsInt("UPLOAD_THUMBNAIL_SIZE", 320),
+		},
 		Log: LogConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
 	}
 
+	var err error
+	if cfg.Reminder.CheckInterval, err = getEnvAsInterval("REMINDER_CHECK_INTERVAL_SECONDS", 60); err != nil {
+		return nil, err
+	}
+
+	if err := loadSLACalendar(&cfg.SLA); err != nil {
+		return nil, err
+	}
+
+	sources, err := getEnvAsWeights("SCORE_SOURCES", "")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Scoring.Sources = sources
+
+	cfg.Server.PublicURL = strings.TrimSuffix(getEnv("SERVER_PUBLIC_URL", "http://127.0.0.1:"+cfg.Server.Port), "/")
+
 	return cfg, nil
 }
 
+func loadSLACalendar(cfg *SLAConfig) error {
+	var err error
+
+	if cfg.CheckInterval, err = getEnvAsInterval("SLA_CHECK_INTERVAL_SECONDS", 60); err != nil {
+		return err
+	}
+	if cfg.Location, err = time.LoadLocation(getEnv("SLA_TIMEZONE", "Europe/Moscow")); err != nil {
+		return fmt.Errorf("некорректный SLA_TIMEZONE: %w", err)
+	}
+	if cfg.WorkdayStart, err = parseClock(getEnv("SLA_WORKDAY_START", "09:00")); err != nil {
+		return fmt.Errorf("некорректный SLA_WORKDAY_START: %w", err)
+	}
+	if cfg.WorkdayEnd, err = parseClock(getEnv("SLA_WORKDAY_END", "18:00")); err != nil {
+		return fmt.Errorf("некорректный SLA_WORKDAY_END: %w", err)
+	}
+	if cfg.WorkdayEnd <= cfg.WorkdayStart {
+		return fmt.Errorf("SLA_WORKDAY_END должен быть позже SLA_WORKDAY_START")
+	}
+
+	// Дни недели в формате ISO: 1 - понедельник, 7 - воскресенье
+	for _, day := range getEnvAsList("SLA_WORKDAYS", "1,2,3,4,5") {
+		n, err := strconv.Atoi(day)
+		if err != nil || n < 1 || n > 7 {
+			return fmt.Errorf("некорректный день недели в SLA_WORKDAYS: %s", day)
+		}
+		cfg.WorkDays = append(cfg.WorkDays, time.Weekday(n%7))
+	}
+	if len(cfg.WorkDays) == 0 {
+		return fmt.Errorf("SLA_WORKDAYS не может быть пустым")
+	}
+
+	for _, day := range getEnvAsList("SLA_HOLIDAYS", "") {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return fmt.Errorf("некорректная дата в SLA_HOLIDAYS: %s", day)
+		}
+		cfg.Holidays = append(cfg.Holidays, day)
+	}
+
+	return nil
+}
+
+// parseClock разбирает время суток в формате HH:MM
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -85,6 +289,47 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvAsList(key, defaultValue string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvAsInterval разбирает интервал в секундах. Интервал должен быть
+// положительным, иначе фоновую проверку нельзя запустить
+func getEnvAsInterval(key string, defaultSeconds int) (time.Duration, error) {
+	seconds := defaultSeconds
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("некорректный %s: %s", key, value)
+		}
+		seconds = n
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("%s должен быть больше нуля", key)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// getEnvAsWeights разбирает список вида "yandex:5,avito:-5"
+func getEnvAsWeights(key, defaultValue string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, item := range getEnvAsList(key, defaultValue) {
+		name, value, ok := strings.Cut(item, ":")
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("некорректный вес в %s: %s", key, item)
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	return weights, nil
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -93,3 +338,21 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,187 @@
+// Package export построчно записывает таблицы в CSV и XLSX, не собирая
+// всю таблицу в памяти.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Форматы выгрузки
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// ErrUnknownFormat возвращается для неподдерживаемого формата выгрузки
+var ErrUnknownFormat = errors.New("формат выгрузки должен быть csv или xlsx")
+
+// DateTimeLayout - формат даты и времени в CSV
+const DateTimeLayout = "2006-01-02 15:04"
+
+// formulaPrefixes - символы, с которых табличные редакторы начинают формулу
+const formulaPrefixes = "=+-@\t\r"
+
+// Writer записывает строки таблицы. Значения - строки, числа, time.Time
+// или nil для пустой ячейки. Close дописывает буферизованные данные
+type Writer interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// New возвращает Writer формата format, который пишет в w
+func New(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case CSV:
+		return NewCSV(w)
+	case XLSX:
+		return NewXLSX(w, sheet)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// ContentType возвращает MIME-тип файла формата format
+func ContentType(format string) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSV возвращает Writer в формате CSV. Файл начинается с метки BOM,
+// чтобы Excel открывал кириллицу в UTF-8
+func NewCSV(w io.Writer) (Writer, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = escapeFormula(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			record[i] = v.Format(DateTimeLayout)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+	// Строки уходят в w сразу, а не копятся в буфере
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula добавляет апостроф перед строкой, которую табличный
+// редактор принял бы за формулу, чтобы значение открылось как текст
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type xlsxWriter struct {
+	w         io.Writer
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	row       int
+	dateStyle int
+	headStyle int
+}
+
+// NewXLSX возвращает Writer в формате XLSX с листом sheet. Первая строка
+// считается заголовком. Строки листа сбрасываются во временный файл,
+// а книга целиком записывается в w при Close
+func NewXLSX(w io.Writer, sheet string) (Writer, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	dateStyle, err := file.NewStyle(&excelize.Style{CustomNumFmt: stringPtr("dd.mm.yyyy hh:mm")})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	headStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{w: w, file: file, stream: stream, dateStyle: dateStyle, headStyle: headStyle}, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []interface{}) error {
+	x.row++
+	if x.row == 1 && len(values) > 0 {
+		// Ширину столбцов нужно задать до первой строки
+		if err := x.stream.SetColWidth(1, len(values), 20); err != nil {
+			return err
+		}
+	}
+
+	// Строки потокового листа записываются как текст, а не формулы,
+	// поэтому экранировать их, как в CSV, не нужно
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok && x.row > 1 {
+			cells[i] = excelize.Cell{StyleID: x.dateStyle, Value: t}
+		} else if x.row == 1 {
+			cells[i] = excelize.Cell{StyleID: x.headStyle, Value: value}
+		} else {
+			cells[i] = value
+		}
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := x.file.WriteTo(x.w)
+	return err
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,79 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var testRows = [][]interface{}{
+	{"ID", "ФИО", "Объем", "Создана"},
+	{1, "Иван, \"Иванов\"", 2.5, time.Date(2024, 10, 1, 9, 30, 0, 0, time.UTC)},
+	{2, "Петр", nil, time.Date(2024, 10, 2, 18, 0, 0, 0, time.UTC)},
+	{3, "=HYPERLINK(\"http://example.com\")", -1.5, nil},
+}
+
+func writeRows(t *testing.T, format string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := New(format, &buf, "Заявки")
+	if err != nil {
+		t.Fatalf("Ошибка создания выгрузки: %v", err)
+	}
+	for _, row := range testRows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("Ошибка записи строки: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Ошибка завершения выгрузки: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	got := string(writeRows(t, CSV))
+	want := "\ufeffID,ФИО,Объем,Создана\n" +
+		"1,\"Иван, \"\"Иванов\"\"\",2.5,2024-10-01 09:30\n" +
+		"2,Петр,,2024-10-02 18:00\n" +
+		"3,\"'=HYPERLINK(\"\"http://example.com\"\")\",-1.5,\n"
+	if got != want {
+		t.Errorf("Неверный CSV:\n%s\nожидалось:\n%s", got, want)
+	}
+}
+
+func TestXLSX(t *testing.T) {
+	book, err := excelize.OpenReader(bytes.NewReader(writeRows(t, XLSX)))
+	if err != nil {
+		t.Fatalf("Ошибка чтения книги: %v", err)
+	}
+	defer book.Close()
+
+	rows, err := book.GetRows("Заявки")
+	if err != nil {
+		t.Fatalf("Ошибка чтения листа: %v", err)
+	}
+	if len(rows) != 4 || rows[0][1] != "ФИО" || rows[1][1] != "Иван, \"Иванов\"" || rows[1][2] != "2.5" {
+		t.Fatalf("Неверное содержимое листа: %q", rows)
+	}
+	if rows[2][3] != "02.10.2024 18:00" {
+		t.Errorf("Ожидалась дата в формате dd.mm.yyyy hh:mm, получено %q", rows[2][3])
+	}
+
+	formula, err := book.GetCellFormula("Заявки", "B4")
+	if err != nil {
+		t.Fatalf("Ошибка чтения ячейки: %v", err)
+	}
+	if formula != "" || rows[3][1] != testRows[3][1] || rows[3][2] != "-1.5" {
+		t.Errorf("Строка, похожая на формулу, должна записываться текстом, получено %q (формула %q)", rows[3], formula)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := New("pdf", &bytes.Buffer{}, "Заявки"); err != ErrUnknownFormat {
+		t.Errorf("Ожидалась ошибка формата, получено %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"calc_example/internal/model"
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Поля multipart-формы с файлами
+const (
+	formFieldFiles           = "files"
+	formFieldPreviousInvoice = "previousInvoiceFile"
+)
+
+// bindIssueForm заполняет заявку из полей multipart-формы (имена полей
+// совпадают с JSON) и возвращает приложенные файлы. Файл в поле
+// previousInvoiceFile считается инвойсом прошлой поставки
+func bindIssueForm(c *gin.Context, req *model.CreateIssueRequest) ([]model.FileUpload, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	// Пустые поля формы считаются непереданными
+	values := make(map[string][]string, len(form.Value))
+	for key, value := range form.Value {
+		if len(value) > 0 && value[0] != "" {
+			values[key] = value
+		}
+	}
+	if err := binding.MapFormWithTag(req, values, "json"); err != nil {
+		return nil, err
+	}
+
+	if invoices := form.File[formFieldPreviousInvoice]; len(invoices) > 0 && req.PreviousInvoiceFile == "" {
+		req.PreviousInvoiceFile = invoices[0].Filename
+	}
+
+	return readUploads(form, formFieldPreviousInvoice, formFieldFiles)
+}
+
+// readUploads читает файлы из указанных полей формы
+func readUploads(form *multipart.Form, fields ...string) ([]model.FileUpload, error) {
+	var files []model.FileUpload
+	for _, field := range fields {
+		for _, header := range form.File[field] {
+			file, err := header.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, model.FileUpload{
+				FileName: header.Filename,
+				Data:     data,
+			})
+		}
+	}
+	return files, nil
+}
+
+// uploadFormOverhead - запас на поля формы сверх допустимого объема файлов
+const uploadFormOverhead = 1 << 20
+
+// limitUploadSize ограничивает размер тела запроса с файлами, чтобы
+// не читать заведомо слишком большие запросы целиком
+func (h *Handler) limitUploadSize(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxUploadSize()+uploadFormOverhead)
+}
+
+// respondUploadError отвечает на ошибку разбора запроса с файлами
+func (h *Handler) respondUploadError(c *gin.Context, err error) {
+	var sizeErr *http.MaxBytesError
+	if errors.As(err, &sizeErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Превышен допустимый размер файлов"})
+		return
+	}
+	h.logger.Error("Ошибка разбора запроса:", err)
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+}
+
+// Attachment handlers
+func (h *Handler) addAttachments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	h.limitUploadSize(c)
+	form, err := c.MultipartForm()
+	if err != nil {
+		h.respondUploadError(c, err)
+		return
+	}
+	files, err := readUploads(form, formFieldPreviousInvoice, formFieldFiles)
+	if err != nil {
+		h.respondUploadError(c, err)
+		return
+	}
+	// Данные инвойса заполняются только из файла в поле previousInvoiceFile
+	var previousInvoiceFile string
+	if invoices := form.File[formFieldPreviousInvoice]; len(invoices) > 0 {
+		previousInvoiceFile = invoices[0].Filename
+	}
+
+	attachments, err := h.service.AddAttachments(uint(id), files, previousInvoiceFile)
+	var validationErr *service.ValidationError
+	if errors.Is(err, service.ErrIssueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrNoFiles) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Недопустимые файлы", "fields": validationErr.Fields})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка сохранения вложений:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachments)
+}
+
+func (h *Handler) downloadAttachment(c *gin.Context) {
+	h.sendAttachment(c, h.service.OpenAttachment)
+}
+
+// downloadThumbnail отдает превью изображения
+func (h *Handler) downloadThumbnail(c *gin.Context) {
+	h.sendAttachment(c, h.service.OpenThumbnail)
+}
+
+// sendAttachment отдает файл вложения, открытый функцией open
+func (h *Handler) sendAttachment(c *gin.Context, open func(id uint) (*service.AttachmentContent, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вложения"})
+		return
+	}
+
+	download, err := open(uint(id))
+	if errors.Is(err, service.ErrAttachmentNotFound) || errors.Is(err, service.ErrThumbnailNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrAttachmentQuarantined) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка получения вложения:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	// Хранилище с временными ссылками отдает файл клиенту напрямую
+	if download.URL != "" {
+		c.Redirect(http.StatusFound, download.URL)
+		return
+	}
+	defer download.Content.Close()
+
+	c.DataFromReader(http.StatusOK, download.Size, download.MimeType, download.Content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": download.FileName}),
+		"ETag":                strconv.Quote(download.ETag),
+	})
+}
+
+// releaseAttachment снимает вложение с карантина
+func (h *Handler) releaseAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID вложения"})
+		return
+	}
+
+	attachment, err := h.service.ReleaseAttachment(uint(id))
+	if errors.Is(err, service.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка снятия вложения с карантина:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
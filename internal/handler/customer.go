@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Customer handlers
+func (h *Handler) getCustomer(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID клиента"})
+		return
+	}
+
+	card, err := h.service.GetCustomerCard(uint(id))
+	if errors.Is(err, service.ErrCustomerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка получения клиента:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *Handler) getCustomerIssues(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID клиента"})
+		return
+	}
+
+	issues, err := h.service.GetCustomerIssues(uint(id))
+	if errors.Is(err, service.ErrCustomerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка получения заявок клиента:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, issues)
+}
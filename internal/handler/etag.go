@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidIfMatch = errors.New("неверный заголовок If-Match")
+
+// setETag передает версию заявки в заголовке ETag
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10)))
+}
+
+// parseIfMatch возвращает версию заявки из заголовка If-Match.
+// Значение "*" соответствует любой версии и возвращается как 0.
+func parseIfMatch(header string) (uint, error) {
+	value := strings.TrimSpace(header)
+	if value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || version == 0 {
+		return 0, errInvalidIfMatch
+	}
+	return uint(version), nil
+}
+
+// requireIfMatch возвращает версию заявки из обязательного заголовка
+// If-Match: изменение возможно только для версии заявки, которую видел
+// клиент. Если заголовка нет или он неверный, отвечает ошибкой и возвращает false.
+// Связи, напоминания и вложения If-Match не требуют: это отдельные записи
+// со своими ID, их добавление и удаление не перезаписывает поля заявки
+// и чужие изменения, поэтому версия заявки от них не меняется
+func requireIfMatch(c *gin.Context) (uint, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "Требуется заголовок If-Match с ETag заявки"})
+		return 0, false
+	}
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, false
+	}
+	return version, true
+}
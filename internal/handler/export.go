@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"calc_example/internal/export"
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export handlers
+
+// exportIssues выгружает заявки в CSV или XLSX. Фильтры и сортировка те же,
+// что у списка заявок, columns выбирает столбцы, lang - язык заголовков
+// (по умолчанию по заголовку Accept-Language)
+func (h *Handler) exportIssues(c *gin.Context) {
+	format := c.DefaultQuery("format", export.CSV)
+	if format != export.CSV && format != export.XLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": export.ErrUnknownFormat.Error()})
+		return
+	}
+
+	filter, err := parseIssueFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var columns []string
+	if value := c.Query("columns"); value != "" {
+		columns = strings.Split(value, ",")
+	}
+
+	lang := c.Query("lang")
+	if lang == "" && strings.HasPrefix(strings.ToLower(c.GetHeader("Accept-Language")), service.ExportLangEN) {
+		lang = service.ExportLangEN
+	}
+
+	issues, err := h.service.ExportIssues(filter, columns, lang)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка выгрузки заявок:", err)
+		return
+	}
+
+	fileName := "issues-" + time.Now().Format("2006-01-02") + "." + format
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Status(http.StatusOK)
+
+	// Заголовки ответа уже отправлены, поэтому ошибку можно только записать в журнал
+	w, err := export.New(format, c.Writer, issues.Title())
+	if err == nil {
+		err = issues.WriteTo(w)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		h.logger.Error("Ошибка выгрузки заявок:", err)
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"calc_example/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidPage       = errors.New("параметры page и limit должны быть целыми числами")
+	errInvalidChinaParam = errors.New("параметр hasChinaExperience должен быть true или false")
+	errInvalidOrder      = errors.New("параметр order должен быть asc или desc")
+)
+
+// parseIssueFilter читает фильтр, сортировку и страницу списка заявок
+// из параметров запроса
+func parseIssueFilter(c *gin.Context) (model.IssueFilter, error) {
+	filter := model.IssueFilter{
+		MatchAllTags: c.Query("tagMatch") == "all",
+		Assignee:     c.Query("assignee"),
+		SortBy:       c.Query("sort"),
+		Cursor:       c.Query("cursor"),
+	}
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	if statuses := c.Query("status"); statuses != "" {
+		filter.Statuses = strings.Split(statuses, ",")
+	}
+
+	if value := c.Query("hasChinaExperience"); value != "" {
+		experience, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, errInvalidChinaParam
+		}
+		filter.HasChinaExperience = &experience
+	}
+
+	switch c.Query("order") {
+	case "", "desc":
+	case "asc":
+		filter.SortAsc = true
+	default:
+		return filter, errInvalidOrder
+	}
+
+	created, err := parsePeriod(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Created = created
+
+	for param, target := range map[string]*int{"page": &filter.Page, "limit": &filter.Limit} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, errInvalidPage
+		}
+		*target = n
+	}
+
+	return filter, nil
+}
+
+// pageLinks возвращает заголовок Link со ссылками на соседние страницы
+// списка: ссылки повторяют параметры запроса с курсором вместо номера страницы
+func pageLinks(c *gin.Context, page *model.IssuePage) string {
+	var links []string
+	for _, link := range []struct{ rel, cursor string }{
+		{"next", page.NextCursor},
+		{"prev", page.PrevCursor},
+	} {
+		if link.cursor == "" {
+			continue
+		}
+		u := *c.Request.URL
+		query := u.Query()
+		query.Del("page")
+		query.Set("cursor", link.cursor)
+		u.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), link.rel))
+	}
+	return strings.Join(links, ", ")
+}
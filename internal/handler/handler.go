@@ -1,31 +1,31 @@
 package handler
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
-	"io"
-	"log"
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 
 	"calc_example/internal/model"
+	"calc_example/internal/notifier"
 	"calc_example/internal/service"
 	"calc_example/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type Handler struct {
-	service *service.Service
-	logger  *logger.Logger
+	service  *service.Service
+	notifier *notifier.Notifier
+	logger   *logger.Logger
 }
 
-func New(service *service.Service, logger *logger.Logger) *Handler {
+func New(service *service.Service, notifier *notifier.Notifier, logger *logger.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:  service,
+		notifier: notifier,
+		logger:   logger,
 	}
 }
 
@@ -36,8 +36,59 @@ func (h *Handler) InitRoutes(router *gin.Engine) {
 		// Заявки
 		api.POST("/issue", h.createIssue)
 		api.GET("/issues", h.getAllIssues)
+		api.GET("/issues/search", h.searchIssues)
+		api.GET("/issues/export", h.exportIssues)
 		api.GET("/issue/:id", h.getIssueByID)
 		api.PATCH("/issue/:id", h.updateIssue)
+		api.GET("/issue/:id/history", h.getIssueHistory)
+		api.POST("/issue/:id/merge", h.mergeIssues)
+		api.POST("/issue/:id/clone", h.cloneIssue)
+
+		// Вложения
+		api.POST("/issue/:id/attachments", h.addAttachments)
+		api.GET("/attachments/:id", h.downloadAttachment)
+		api.GET("/attachments/:id/thumbnail", h.downloadThumbnail)
+		api.POST("/attachments/:id/release", h.releaseAttachment)
+
+		// Инвойс поставщика
+		api.POST("/issue/:id/invoice/confirm", h.confirmInvoice)
+
+		// Связи между заявками
+		api.POST("/issue/:id/links", h.createIssueLink)
+		api.DELETE("/issue/:id/links/:linkId", h.deleteIssueLink)
+
+		// Клиенты
+		api.GET("/customers/:id", h.getCustomer)
+		api.GET("/customers/:id/issues", h.getCustomerIssues)
+
+		// Товары по ссылкам на маркетплейсы
+		api.GET("/products", h.getProductGroups)
+		api.GET("/products/:marketplace/:productId/issues", h.getProductIssues)
+
+		// Отчеты
+		api.GET("/reports/sources", h.getSourceReport)
+		api.GET("/reports/loss-reasons", h.getLossReasonReport)
+
+		// Причины проигрыша
+		api.GET("/loss-reasons", h.getAllLossReasons)
+		api.POST("/loss-reasons", h.createLossReason)
+
+		// Корзина
+		api.DELETE("/issue/:id", h.deleteIssue)
+		api.GET("/issues/trash", h.getDeletedIssues)
+		api.POST("/issue/:id/restore", h.restoreIssue)
+		api.DELETE("/issue/:id/purge", h.purgeIssue)
+
+		// Теги
+		api.GET("/tags", h.getAllTags)
+		api.POST("/issue/:id/tags", h.addIssueTags)
+		api.DELETE("/issue/:id/tags/:tag", h.removeIssueTag)
+
+		// Менеджер и напоминания
+		api.PUT("/issue/:id/assignee", h.assignIssue)
+		api.POST("/issue/:id/reminders", h.createReminder)
+		api.GET("/issue/:id/reminders", h.getIssueReminders)
+		api.DELETE("/issue/:id/reminders/:reminderId", h.deleteReminder)
 	}
 
 	// Health check
@@ -46,22 +97,38 @@ func (h *Handler) InitRoutes(router *gin.Engine) {
 
 // Issue handlers
 func (h *Handler) createIssue(c *gin.Context) {
+	// Данные проверяются в сервисе, чтобы вернуть ошибки по полям
 	var req model.CreateIssueRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Ошибка валидации запроса:", err)
+	var files []model.FileUpload
+	var err error
+	if c.ContentType() == binding.MIMEMultipartPOSTForm {
+		h.limitUploadSize(c)
+		if files, err = bindIssueForm(c, &req); err != nil {
+			h.respondUploadError(c, err)
+			return
+		}
+	} else {
+		err = json.NewDecoder(c.Request.Body).Decode(&req)
+	}
+	if err != nil {
+		h.logger.Error("Ошибка разбора запроса:", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
 		return
 	}
 
-	issue, err := h.service.CreateIssue(&req)
+	issue, err := h.service.CreateIssueWithFiles(&req, files)
 	if err != nil {
-		h.logger.Error("Ошибка создания заявки:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondIssueError(c, "Ошибка создания заявки:", err)
 		return
 	}
 
-	// Отправка сообщения в Telegram
-	err = sendTelegramMessage(issue) // Передаем req для формирования сообщения
+	// Отправка сообщения в Telegram. О повторной заявке сообщаем
+	// со ссылкой на исходную, а не как о новом клиенте
+	if issue.DuplicateOfID != nil {
+		err = h.notifier.DuplicateIssue(issue)
+	} else {
+		err = h.notifier.NewIssue(issue)
+	}
 	if err != nil {
 		h.logger.Error("Ошибка отправки сообщения в Telegram:", err)
 	}
@@ -69,119 +136,150 @@ func (h *Handler) createIssue(c *gin.Context) {
 	c.JSON(http.StatusCreated, issue)
 }
 
-func sendTelegramMessage(issue *model.IssueResponse) error {
-	url := os.Getenv("TELEGRAM_BOT_SERVICE") + "/send-message"
-	log.Println(url)
-
-	host := os.Getenv("FRONTEND_HOST")
-	if host == "" {
-		host = "http://127.0.0.1"
-	}
-	port := os.Getenv("FRONTEND_PORT")
-	if port == "" {
-		port = "8081"
-	}
-
-	issueLink := fmt.Sprintf("%s:%s/#/issues/%d", host, port, issue.ID)
-	log.Println(issueLink)
-	log.Println(fmt.Sprintf("<a href=\"%s\">Открыть заявку!</a>", issueLink))
-
-	message := fmt.Sprintf("🆕 <b>Новая заявка</b>\n\n"+
-		"👤 Имя: %s\n"+
-		"📞 Телефон: %s\n\n"+
-		"📦 Товар: %s\n"+
-		"📲 Источник: %s\n\n"+
-		"🧑🏻‍💻 Менеджер: %s\n"+
-		"📌 Статус: %s\n\n"+
-		"🔗 <a href=\"%s\">Открыть заявку!</a>",
-		issue.FullName,
-		issue.ContactInfo,
-		issue.ProductDescription,
-		"Сайт",
-		"Виртуальный помощник",
-		"Ожидает ответа",
-		issueLink,
-	)
-
-	data := map[string]string{"text": message}
-
-	jsonData, err := json.Marshal(data)
+func (h *Handler) getAllIssues(c *gin.Context) {
+	filter, err := parseIssueFilter(c)
 	if err != nil {
-		return fmt.Errorf("_ошибка при преобразовании в JSON: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	client := &http.Client{}
-
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	page, err := h.service.GetAllIssues(filter)
 	if err != nil {
-		return fmt.Errorf("__ошибка при создании запроса: %v", err)
+		h.respondIssueError(c, "Ошибка получения заявок:", err)
+		return
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if links := pageLinks(c, page); links != "" {
+		c.Header("Link", links)
+	}
+	c.JSON(http.StatusOK, page.Items)
+}
 
-	resp, err := client.Do(httpReq)
+func (h *Handler) getIssueByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		return fmt.Errorf("___ошибка при выполнении запроса: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body) // Читаем тело ответа для диагностики
-		return fmt.Errorf("неуспешный статус ответа: %s, тело: %s", resp.Status, string(body))
+	issue, err := h.service.GetIssueByID(uint(id))
+	if errors.Is(err, service.ErrIssueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка получения заявки:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
 	}
 
-	return nil
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
 }
 
-func (h *Handler) getAllIssues(c *gin.Context) {
-	issues, err := h.service.GetAllIssues()
+// updateIssue применяет к заявке документ JSON Merge Patch (RFC 7396)
+func (h *Handler) updateIssue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		h.logger.Error("Ошибка получения заявок:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	issue, err := h.service.PatchIssue(uint(id), patch, version)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка обновления заявки:", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, issues)
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
 }
 
-func (h *Handler) getIssueByID(c *gin.Context) {
+// respondIssueError отвечает на ошибку изменения заявки подходящим статусом
+func (h *Handler) respondIssueError(c *gin.Context, message string, err error) {
+	var validationErr *service.ValidationError
+	var conflictErr *service.MergeConflictError
+	switch {
+	case errors.Is(err, service.ErrIssueNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса", "fields": validationErr.Fields})
+	case errors.As(err, &conflictErr):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflictErr.Fields})
+	case errors.Is(err, service.ErrInvalidPatch), errors.Is(err, service.ErrMergeSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+	}
+}
+
+// cloneIssue создает копию заявки :id для повторного заказа. Тело запроса
+// необязательно и содержит поля, которые нужно заменить в копии
+func (h *Handler) cloneIssue(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
 		return
 	}
 
-	issue, err := h.service.GetIssueByID(uint(id))
+	overrides, err := c.GetRawData()
 	if err != nil {
-		h.logger.Error("Ошибка получения заявки:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		h.logger.Error("Ошибка чтения запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
 		return
 	}
 
-	c.JSON(http.StatusOK, issue)
+	issue, err := h.service.CloneIssue(uint(id), overrides)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка копирования заявки:", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, issue)
 }
 
-func (h *Handler) updateIssue(c *gin.Context) {
+// mergeIssues объединяет заявки из тела запроса с заявкой :id
+func (h *Handler) mergeIssues(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
 		return
 	}
 
-	var req model.UpdateIssueRequest
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req model.MergeIssuesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Error("Ошибка валидации запроса:", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
 		return
 	}
 
-	issue, err := h.service.UpdateIssue(uint(id), &req)
+	issue, err := h.service.MergeIssues(uint(id), &req, version)
 	if err != nil {
-		h.logger.Error("Ошибка обновления заявки:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondIssueError(c, "Ошибка объединения заявок:", err)
 		return
 	}
 
+	setETag(c, issue.Version)
 	c.JSON(http.StatusOK, issue)
 }
 
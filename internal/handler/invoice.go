@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// confirmInvoice подтверждает данные инвойса заявки :id. Тело запроса
+// (необязательное) исправляет извлеченные из файла значения
+func (h *Handler) confirmInvoice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		h.logger.Error("Ошибка чтения запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	issue, err := h.service.ConfirmInvoice(uint(id), patch, version)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка подтверждения инвойса:", err)
+		return
+	}
+
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
+}
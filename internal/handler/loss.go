@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"calc_example/internal/model"
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Loss Reason handlers
+func (h *Handler) getAllLossReasons(c *gin.Context) {
+	reasons, err := h.service.GetAllLossReasons()
+	if err != nil {
+		h.logger.Error("Ошибка получения причин проигрыша:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reasons)
+}
+
+func (h *Handler) createLossReason(c *gin.Context) {
+	var req model.CreateLossReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Ошибка валидации запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	reason, err := h.service.CreateLossReason(&req)
+	if errors.Is(err, service.ErrLossReasonExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка создания причины проигрыша:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reason)
+}
+
+func (h *Handler) getLossReasonReport(c *gin.Context) {
+	period, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.service.GetLossReasonReport(period, c.Query("interval"))
+	if errors.Is(err, service.ErrInvalidReportInterval) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка построения отчета по причинам проигрыша:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
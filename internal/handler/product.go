@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Product handlers
+
+// getProductGroups возвращает товары, на которые ссылаются заявки.
+// Параметр minIssues оставляет товары, которые встречаются в нескольких заявках
+func (h *Handler) getProductGroups(c *gin.Context) {
+	minIssues := 1
+	if value := c.Query("minIssues"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное значение minIssues"})
+			return
+		}
+		minIssues = parsed
+	}
+
+	groups, err := h.service.GetProductGroups(minIssues)
+	if err != nil {
+		h.logger.Error("Ошибка получения товаров:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+func (h *Handler) getProductIssues(c *gin.Context) {
+	issues, err := h.service.GetProductIssues(c.Param("marketplace"), c.Param("productId"))
+	if err != nil {
+		h.logger.Error("Ошибка получения заявок по товару:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, issues)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"calc_example/internal/model"
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reminder handlers
+func (h *Handler) assignIssue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req model.AssignIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Ошибка валидации запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	issue, err := h.service.AssignIssue(uint(id), &req, version)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка назначения менеджера:", err)
+		return
+	}
+
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
+}
+
+func (h *Handler) createReminder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	var req model.CreateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Ошибка валидации запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	reminder, err := h.service.CreateReminder(uint(id), &req)
+	if errors.Is(err, service.ErrIssueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка создания напоминания:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reminder)
+}
+
+func (h *Handler) getIssueReminders(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	reminders, err := h.service.GetIssueReminders(uint(id))
+	if errors.Is(err, service.ErrIssueNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка получения напоминаний:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
+
+func (h *Handler) deleteReminder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	reminderID, err := strconv.ParseUint(c.Param("reminderId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID напоминания"})
+		return
+	}
+
+	err = h.service.DeleteReminder(uint(id), uint(reminderID))
+	if errors.Is(err, service.ErrReminderNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка удаления напоминания:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Напоминание удалено"})
+}
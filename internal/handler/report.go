@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"calc_example/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidPeriod = errors.New("неверный период: ожидаются даты from и to в формате YYYY-MM-DD")
+
+// parsePeriod разбирает период отчета из параметров from и to (включительно)
+func parsePeriod(c *gin.Context) (model.ReportPeriod, error) {
+	var period model.ReportPeriod
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return period, errInvalidPeriod
+		}
+		period.From = &t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return period, errInvalidPeriod
+		}
+		t = t.AddDate(0, 0, 1)
+		period.To = &t
+	}
+
+	return period, nil
+}
+
+// Report handlers
+func (h *Handler) getSourceReport(c *gin.Context) {
+	period, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := h.service.GetSourceReport(period)
+	if err != nil {
+		h.logger.Error("Ошибка построения отчета по источникам:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rows)
+}
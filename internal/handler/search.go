@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search handlers
+
+// searchIssues ищет заявки по словам запроса q. Параметр limit
+// ограничивает число результатов
+func (h *Handler) searchIssues(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное значение limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.service.SearchIssues(c.Query("q"), limit)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка поиска заявок:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"calc_example/internal/model"
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tag handlers
+func (h *Handler) getAllTags(c *gin.Context) {
+	tags, err := h.service.GetAllTags()
+	if err != nil {
+		h.logger.Error("Ошибка получения тегов:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *Handler) addIssueTags(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req model.IssueTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("Ошибка валидации запроса:", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные запроса"})
+		return
+	}
+
+	issue, err := h.service.AddIssueTags(uint(id), req.Tags, version)
+	if err != nil {
+		h.respondIssueError(c, "Ошибка добавления тегов:", err)
+		return
+	}
+
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
+}
+
+func (h *Handler) removeIssueTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	issue, err := h.service.RemoveIssueTag(uint(id), c.Param("tag"), version)
+	if errors.Is(err, service.ErrTagNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.respondIssueError(c, "Ошибка удаления тега:", err)
+		return
+	}
+
+	setETag(c, issue.Version)
+	c.JSON(http.StatusOK, issue)
+}
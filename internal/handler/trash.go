@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"calc_example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Trash handlers
+func (h *Handler) deleteIssue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteIssue(uint(id), version); err != nil {
+		h.respondIssueError(c, "Ошибка удаления заявки:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка перемещена в корзину"})
+}
+
+func (h *Handler) getDeletedIssues(c *gin.Context) {
+	issues, err := h.service.GetDeletedIssues()
+	if err != nil {
+		h.logger.Error("Ошибка получения корзины:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, issues)
+}
+
+func (h *Handler) restoreIssue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	issue, err := h.service.RestoreIssue(uint(id))
+	if errors.Is(err, service.ErrIssueNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIssueMerged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка восстановления заявки:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, issue)
+}
+
+func (h *Handler) purgeIssue(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	err = h.service.PurgeIssue(uint(id))
+	if errors.Is(err, service.ErrIssueNotInTrash) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Ошибка окончательного удаления заявки:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заявка удалена окончательно"})
+}
+
+func (h *Handler) getIssueHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный ID заявки"})
+		return
+	}
+
+	history, err := h.service.GetIssueHistory(uint(id))
+	if err != nil {
+		h.logger.Error("Ошибка получения истории заявки:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,333 @@
+// Package invoice извлекает из инвойсов поставщиков (XLSX, CSV, PDF
+// с текстовым слоем) поставщика, валюту, позиции и итоговую сумму.
+// Разбор эвристический: результат предназначен для проверки менеджером.
+package invoice
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported возвращается для файлов, из которых данные не извлекаются
+var ErrUnsupported = errors.New("формат файла не поддерживается")
+
+// Line - позиция инвойса
+type Line struct {
+	Description string
+	Quantity    *float64
+	UnitPrice   *float64
+	Amount      *float64
+}
+
+// Invoice - данные, найденные в инвойсе
+type Invoice struct {
+	Supplier string
+	Currency string
+	Total    *float64
+	Lines    []Line
+}
+
+// Empty сообщает, что в файле не найдено ничего полезного
+func (inv *Invoice) Empty() bool {
+	return inv.Supplier == "" && inv.Total == nil && len(inv.Lines) == 0
+}
+
+// Parse разбирает инвойс с типом содержимого mimeType
+func Parse(data []byte, mimeType string) (*Invoice, error) {
+	var rows [][]string
+	var err error
+
+	switch {
+	case strings.HasPrefix(mimeType, "application/pdf"):
+		rows, err = pdfRows(data)
+	case strings.HasPrefix(mimeType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"):
+		rows, err = xlsxRows(data)
+	case strings.HasPrefix(mimeType, "text/csv"), strings.HasPrefix(mimeType, "text/plain"):
+		rows, err = csvRows(data)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRows(rows), nil
+}
+
+// Роли столбцов таблицы позиций
+const (
+	columnDescription = iota
+	columnQuantity
+	columnUnitPrice
+	columnAmount
+	columnCount
+)
+
+// columnKeywords - слова в заголовках столбцов. Порядок проверки важен:
+// "Total price" - это сумма, а не цена
+var columnKeywords = []struct {
+	column   int
+	keywords []string
+}{
+	{columnQuantity, []string{"qty", "quantity", "pcs", "кол-во", "количество", "кол.", "数量"}},
+	{columnAmount, []string{"amount", "total", "sum", "сумма", "стоимость", "金额", "总价", "总额"}},
+	{columnUnitPrice, []string{"price", "цена", "单价"}},
+	{columnDescription, []string{"description", "product", "goods", "item", "name", "наименование", "товар", "описание", "品名", "名称", "货物"}},
+}
+
+// codeKeywords - заголовки столбцов с номерами и артикулами
+var codeKeywords = []string{"no.", "№", "#", "code", "артикул", "sku"}
+
+var (
+	supplierPattern = regexp.MustCompile(`(?i)^(?:supplier|seller|exporter|shipper|vendor|поставщик|продавец|отправитель|卖方|供应商|出口商)\s*[:：]?\s*(.*)$`)
+	totalPattern    = regexp.MustCompile(`(?i)^(?:grand\s+total|total|итого|всего|合计|总计)`)
+	currencyPattern = regexp.MustCompile(`(?i)\b(USD|CNY|RMB|EUR|RUB)\b|([$¥€₽])`)
+	numberPattern   = regexp.MustCompile(`-?\d[\d\s.,]*`)
+)
+
+// currencySymbols - коды валют по символам
+var currencySymbols = map[string]string{
+	"$":   "USD",
+	"¥":   "CNY",
+	"€":   "EUR",
+	"₽":   "RUB",
+	"RMB": "CNY",
+}
+
+// parseRows ищет в строках таблицы поставщика, валюту, таблицу позиций
+// и итоговую сумму
+func parseRows(rows [][]string) *Invoice {
+	inv := &Invoice{}
+	for i := range rows {
+		for j := range rows[i] {
+			rows[i][j] = strings.TrimSpace(rows[i][j])
+		}
+	}
+
+	for _, row := range rows {
+		if inv.Supplier == "" {
+			inv.Supplier = findSupplier(row)
+		}
+		if inv.Currency == "" {
+			inv.Currency = findCurrency(row)
+		}
+	}
+
+	header, columns := findHeader(rows)
+	if header < 0 {
+		return inv
+	}
+
+	for _, row := range rows[header+1:] {
+		if isEmptyRow(row) {
+			if len(inv.Lines) > 0 {
+				break
+			}
+			continue
+		}
+		if isTotalRow(row) {
+			inv.Total = rowTotal(row, columns)
+			break
+		}
+		if line, ok := parseLine(row, columns); ok {
+			inv.Lines = append(inv.Lines, line)
+		}
+	}
+
+	if inv.Total == nil {
+		inv.Total = sumAmounts(inv.Lines)
+	}
+	return inv
+}
+
+func findSupplier(row []string) string {
+	for i, cell := range row {
+		match := supplierPattern.FindStringSubmatch(cell)
+		if match == nil {
+			continue
+		}
+		if name := strings.TrimSpace(match[1]); name != "" {
+			return name
+		}
+		for _, next := range row[i+1:] {
+			if next != "" {
+				return next
+			}
+		}
+	}
+	return ""
+}
+
+func findCurrency(row []string) string {
+	for _, cell := range row {
+		match := currencyPattern.FindStringSubmatch(cell)
+		if match == nil {
+			continue
+		}
+		code := strings.ToUpper(match[1])
+		if code == "" {
+			code = match[2]
+		}
+		if mapped, ok := currencySymbols[code]; ok {
+			return mapped
+		}
+		return code
+	}
+	return ""
+}
+
+// findHeader возвращает номер строки заголовка таблицы позиций и номера
+// столбцов по ролям. Заголовок должен содержать описание товара
+// и количество или сумму
+func findHeader(rows [][]string) (int, [columnCount]int) {
+	for i, row := range rows {
+		columns := [columnCount]int{-1, -1, -1, -1}
+		for j, cell := range row {
+			if column, ok := classifyHeader(cell); ok && columns[column] < 0 {
+				columns[column] = j
+			}
+		}
+		if columns[columnDescription] >= 0 && (columns[columnQuantity] >= 0 || columns[columnAmount] >= 0) {
+			return i, columns
+		}
+	}
+	return -1, [columnCount]int{}
+}
+
+func classifyHeader(cell string) (int, bool) {
+	cell = strings.ToLower(cell)
+	if cell == "" || containsAny(cell, codeKeywords) {
+		return 0, false
+	}
+	for _, group := range columnKeywords {
+		if containsAny(cell, group.keywords) {
+			return group.column, true
+		}
+	}
+	return 0, false
+}
+
+func parseLine(row []string, columns [columnCount]int) (Line, bool) {
+	line := Line{
+		Description: cellAt(row, columns[columnDescription]),
+		Quantity:    parseNumber(cellAt(row, columns[columnQuantity])),
+		UnitPrice:   parseNumber(cellAt(row, columns[columnUnitPrice])),
+		Amount:      parseNumber(cellAt(row, columns[columnAmount])),
+	}
+	if line.Amount == nil && line.Quantity != nil && line.UnitPrice != nil {
+		amount := round(*line.Quantity * *line.UnitPrice)
+		line.Amount = &amount
+	}
+	return line, line.Description != "" && (line.Quantity != nil || line.Amount != nil)
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isTotalRow(row []string) bool {
+	for _, cell := range row {
+		if cell != "" {
+			return totalPattern.MatchString(cell)
+		}
+	}
+	return false
+}
+
+// rowTotal берет итог из столбца суммы, а если его там нет - последнее
+// число в строке
+func rowTotal(row []string, columns [columnCount]int) *float64 {
+	if total := parseNumber(cellAt(row, columns[columnAmount])); total != nil {
+		return total
+	}
+	for i := len(row) - 1; i >= 0; i-- {
+		if total := parseNumber(numberPattern.FindString(row[i])); total != nil {
+			return total
+		}
+	}
+	return nil
+}
+
+func sumAmounts(lines []Line) *float64 {
+	if len(lines) == 0 {
+		return nil
+	}
+	var total float64
+	for _, line := range lines {
+		if line.Amount == nil {
+			return nil
+		}
+		total += *line.Amount
+	}
+	total = round(total)
+	return &total
+}
+
+func cellAt(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return row[index]
+}
+
+// parseNumber разбирает число в любом из распространенных форматов:
+// "1,234.50", "1 234,50", "$12.5". Пустая строка и текст дают nil
+func parseNumber(value string) *float64 {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',', r == '-':
+			return r
+		case r == ' ', r == '\u00a0', r == '\u202f', r == '\u2009', r == '\'':
+			return -1
+		case strings.ContainsRune("$¥€₽", r):
+			return -1
+		}
+		return 'x'
+	}, strings.TrimSpace(value))
+	value = strings.TrimRight(strings.TrimLeft(value, "x"), "x")
+	if value == "" || strings.Contains(value, "x") {
+		return nil
+	}
+
+	dot, comma := strings.LastIndex(value, "."), strings.LastIndex(value, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.Replace(value, ",", ".", 1)
+	case dot >= 0 && comma >= 0:
+		value = strings.ReplaceAll(value, ",", "")
+	case comma >= 0 && thousandsPattern.MatchString(value):
+		value = strings.ReplaceAll(value, ",", "")
+	case comma >= 0:
+		value = strings.Replace(value, ",", ".", 1)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
+var thousandsPattern = regexp.MustCompile(`^-?\d{1,3}(,\d{3})+$`)
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,168 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// newTestPDF собирает одностраничный PDF, в котором каждая ячейка
+// строки выводится отдельным блоком текста
+func newTestPDF(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+
+	var content strings.Builder
+	for i, row := range rows {
+		for j, cell := range row {
+			fmt.Fprintf(&content, "BT /F1 10 Tf %d %d Td (%s) Tj ET\n", 40+j*130, 800-i*20, cell)
+		}
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func newTestXLSX(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+
+	book := excelize.NewFile()
+	defer book.Close()
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := book.SetSheetRow("Sheet1", cell, &values); err != nil {
+			t.Fatalf("Ошибка заполнения книги: %v", err)
+		}
+	}
+
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatalf("Ошибка сохранения книги: %v", err)
+	}
+	return buf.Bytes()
+}
+
+var testRows = [][]string{
+	{"Commercial Invoice"},
+	{"Seller:", "Yiwu Trading Co"},
+	{"Currency: USD"},
+	{},
+	{"No.", "Description", "Qty", "Unit Price", "Amount"},
+	{"1", "LED lamp", "100", "2.50", "250.00"},
+	{"2", "Power adapter", "20", "1.25", ""},
+	{"Total", "", "", "", "275.00"},
+}
+
+func TestParse(t *testing.T) {
+	var csvData strings.Builder
+	for _, row := range testRows {
+		csvData.WriteString(strings.Join(row, ";") + "\n")
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+	}{
+		{"CSV", []byte(csvData.String()), "text/csv"},
+		{"XLSX", newTestXLSX(t, testRows), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{"PDF", newTestPDF(t, testRows), "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := Parse(tt.data, tt.mimeType)
+			if err != nil {
+				t.Fatalf("Ошибка разбора: %v", err)
+			}
+
+			if inv.Supplier != "Yiwu Trading Co" || inv.Currency != "USD" {
+				t.Errorf("Неверные поставщик и валюта: %q %q", inv.Supplier, inv.Currency)
+			}
+			if inv.Total == nil || *inv.Total != 275 {
+				t.Errorf("Ожидался итог 275, получено %v", inv.Total)
+			}
+			if len(inv.Lines) != 2 {
+				t.Fatalf("Ожидалось две позиции, получено %+v", inv.Lines)
+			}
+			line := inv.Lines[1]
+			if line.Description != "Power adapter" || *line.Quantity != 20 || *line.UnitPrice != 1.25 || *line.Amount != 25 {
+				t.Errorf("Неверная позиция: %+v", line)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("\x89PNG"), "image/png"); err != ErrUnsupported {
+		t.Errorf("Ожидалась ошибка неподдерживаемого формата, получено %v", err)
+	}
+}
+
+func TestParseRowsRussian(t *testing.T) {
+	inv := parseRows([][]string{
+		{"Поставщик: ООО Ромашка"},
+		{"Наименование", "Кол-во", "Цена, ₽", "Сумма, ₽"},
+		{"Кабель", "1 000", "12,50", "12 500,00"},
+		{"Итого:", "", "", "12 500,00"},
+	})
+
+	if inv.Supplier != "ООО Ромашка" || inv.Currency != "RUB" {
+		t.Errorf("Неверные поставщик и валюта: %q %q", inv.Supplier, inv.Currency)
+	}
+	if len(inv.Lines) != 1 || *inv.Lines[0].Quantity != 1000 || *inv.Lines[0].Amount != 12500 {
+		t.Errorf("Неверные позиции: %+v", inv.Lines)
+	}
+	if inv.Total == nil || *inv.Total != 12500 {
+		t.Errorf("Ожидался итог 12500, получено %v", inv.Total)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]float64{
+		"1,234.50": 1234.5,
+		"1.234,50": 1234.5,
+		"1 234,50": 1234.5,
+		"1,234":    1234,
+		"12,5":     12.5,
+		"$12.50":   12.5,
+		"USD 100":  100,
+		"100 pcs":  100,
+		"-3":       -3,
+	}
+	for input, want := range tests {
+		if got := parseNumber(input); got == nil || *got != want {
+			t.Errorf("parseNumber(%q) = %v, ожидалось %v", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "LED lamp", "-"} {
+		if got := parseNumber(input); got != nil {
+			t.Errorf("parseNumber(%q) = %v, ожидалось nil", input, *got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package invoice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// pdfCellSeparator разделяет ячейки в строке PDF, выведенной одним блоком текста
+var pdfCellSeparator = regexp.MustCompile(`\s{2,}|\t`)
+
+// pdfLineTolerance - допустимое расхождение высоты глифов одной строки
+const pdfLineTolerance = 2
+
+// csvDelimiters - разделители, которые пробуются при чтении CSV
+var csvDelimiters = []rune{';', ',', '\t'}
+
+// xlsxRows читает строки всех листов книги подряд
+func xlsxRows(data []byte) ([][]string, error) {
+	book, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+
+	var rows [][]string
+	for _, sheet := range book.GetSheetList() {
+		sheetRows, err := book.GetRows(sheet)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, sheetRows...)
+		// Пустая строка отделяет листы, чтобы таблицы не склеивались
+		rows = append(rows, nil)
+	}
+	return rows, nil
+}
+
+// csvRows читает CSV, выбирая разделитель, который дает больше всего столбцов
+func csvRows(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var best [][]string
+	var bestErr error
+	bestColumns := 0
+	for _, delimiter := range csvDelimiters {
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.Comma = delimiter
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		rows, err := reader.ReadAll()
+		if err != nil {
+			bestErr = err
+			continue
+		}
+		if columns := maxColumns(rows); columns > bestColumns {
+			best, bestColumns = rows, columns
+		}
+	}
+	if best == nil {
+		return nil, bestErr
+	}
+	return best, nil
+}
+
+// pdfRows читает текстовый слой PDF построчно. Глифы с одинаковой высотой
+// образуют строку, разрыв по горизонтали шире кегля начинает новую ячейку
+func pdfRows(data []byte) (rows [][]string, err error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Разбор потока содержимого паникует на поврежденных файлах
+	defer func() {
+		if r := recover(); r != nil {
+			rows, err = nil, fmt.Errorf("ошибка чтения PDF: %v", r)
+		}
+	}()
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		rows = append(rows, pdfPageRows(page.Content().Text)...)
+	}
+	return rows, nil
+}
+
+// pdfCell - ячейка строки PDF и горизонтальная позиция ее начала
+type pdfCell struct {
+	x    float64
+	text strings.Builder
+}
+
+func pdfPageRows(glyphs []pdf.Text) [][]string {
+	// Глифы группируются по строкам в порядке потока, чтобы не зависеть от
+	// наличия ширин у шрифта
+	var lines [][]pdf.Text
+	for _, glyph := range glyphs {
+		found := false
+		for i, line := range lines {
+			if math.Abs(line[0].Y-glyph.Y) < pdfLineTolerance {
+				lines[i] = append(lines[i], glyph)
+				found = true
+				break
+			}
+		}
+		if !found {
+			lines = append(lines, []pdf.Text{glyph})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i][0].Y > lines[j][0].Y
+	})
+
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		var cells []*pdfCell
+		var end float64
+		for _, glyph := range line {
+			size := math.Max(glyph.FontSize, 1)
+			gap := glyph.X - end
+			switch {
+			case len(cells) == 0 || math.Abs(gap) > size*1.5:
+				cells = append(cells, &pdfCell{x: glyph.X})
+			case gap > size*0.2:
+				cells[len(cells)-1].text.WriteByte(' ')
+			}
+			cells[len(cells)-1].text.WriteString(glyph.S)
+			end = glyph.X + glyph.W
+		}
+		sort.SliceStable(cells, func(i, j int) bool {
+			return cells[i].x < cells[j].x
+		})
+
+		var row []string
+		for _, cell := range cells {
+			if text := strings.TrimSpace(cell.text.String()); text != "" {
+				row = append(row, text)
+			}
+		}
+		if len(row) == 1 {
+			row = pdfCellSeparator.Split(row[0], -1)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func maxColumns(rows [][]string) int {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,183 @@
+// Package marketplace находит в тексте ссылки на товары, распознает
+// маркетплейсы 1688, Taobao, Alibaba, Pinduoduo, Ozon и Wildberries
+// и извлекает из ссылок идентификатор товара.
+package marketplace
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Маркетплейсы
+const (
+	Alibaba1688 = "1688"
+	Taobao      = "taobao"
+	Alibaba     = "alibaba"
+	Pinduoduo   = "pinduoduo"
+	Ozon        = "ozon"
+	Wildberries = "wildberries"
+	// Ссылка на другой сайт
+	Other = "other"
+)
+
+// ErrInvalidURL возвращается для ссылок, которые не являются адресом сайта
+var ErrInvalidURL = errors.New("недопустимая ссылка")
+
+// Link - распознанная ссылка на товар. ProductID пуст, если ссылка ведет
+// не на карточку товара или является короткой ссылкой
+type Link struct {
+	URL         string
+	Marketplace string
+	ProductID   string
+}
+
+// Кандидаты в ссылки: адрес со схемой или домен популярной зоны без схемы.
+// Китайские скобки и знаки препинания, которыми обрамляют ссылки при
+// пересылке из приложений, в ссылку не входят
+var candidatePattern = regexp.MustCompile(`(?i)[a-z][a-z0-9+.-]*://[^\s,;<>"'，；【】]+|\b(?:[a-z0-9-]+\.)+(?:com|ru|cn|hk|by|kz)\b(?:/[^\s,;<>"'，；【】]*)?`)
+
+// rule - правило распознавания маркетплейса
+type rule struct {
+	marketplace string
+	domains     []string
+	// Параметры запроса с идентификатором товара
+	params []string
+	// Шаблоны пути с идентификатором товара в первой группе
+	paths []*regexp.Regexp
+}
+
+var rules = []rule{
+	{
+		marketplace: Alibaba1688,
+		domains:     []string{"1688.com"},
+		params:      []string{"offerId"},
+		paths:       []*regexp.Regexp{regexp.MustCompile(`/offer/(\d+)\.html`)},
+	},
+	{
+		// Товары Tmall и Taobao имеют общие идентификаторы
+		marketplace: Taobao,
+		domains:     []string{"taobao.com", "tmall.com", "tmall.hk", "tb.cn"},
+		params:      []string{"id", "itemId"},
+		paths:       []*regexp.Regexp{regexp.MustCompile(`/item/(\d+)\.htm`)},
+	},
+	{
+		marketplace: Alibaba,
+		domains:     []string{"alibaba.com"},
+		paths: []*regexp.Regexp{
+			regexp.MustCompile(`/product-detail/[^/]*?_?(\d{6,})\.html`),
+			regexp.MustCompile(`/product/(\d+)`),
+		},
+	},
+	{
+		marketplace: Pinduoduo,
+		domains:     []string{"pinduoduo.com", "yangkeduo.com"},
+		params:      []string{"goods_id"},
+	},
+	{
+		marketplace: Ozon,
+		domains:     []string{"ozon.ru", "ozon.com", "ozon.by", "ozon.kz"},
+		paths:       []*regexp.Regexp{regexp.MustCompile(`/product/(?:[^/]*-)?(\d+)(?:/|$)`)},
+	},
+	{
+		marketplace: Wildberries,
+		domains:     []string{"wildberries.ru", "wildberries.by", "wildberries.kz", "wb.ru"},
+		params:      []string{"nm"},
+		paths:       []*regexp.Regexp{regexp.MustCompile(`/catalog/(\d+)(?:/|$)`)},
+	},
+}
+
+// Extract находит в тексте ссылки и распознает их. Повторяющиеся ссылки
+// на один товар возвращаются один раз. invalid - найденные в тексте
+// адреса, которые не удалось разобрать
+func Extract(text string) (links []Link, invalid []string) {
+	seen := make(map[string]bool)
+	for _, candidate := range candidatePattern.FindAllString(text, -1) {
+		candidate = strings.TrimRight(candidate, ".,:!?)»")
+
+		link, err := Parse(candidate)
+		if err != nil {
+			invalid = append(invalid, candidate)
+			continue
+		}
+
+		key := link.Marketplace + ":" + link.ProductID
+		if link.ProductID == "" {
+			key = link.URL
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, *link)
+	}
+	return links, invalid
+}
+
+// Parse разбирает ссылку. Ссылка без схемы считается ссылкой https
+func Parse(raw string) (*Link, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(host, ".") || strings.HasSuffix(host, ".") {
+		return nil, ErrInvalidURL
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	link := &Link{URL: u.String(), Marketplace: Other}
+	for _, r := range rules {
+		if !matchesDomain(host, r.domains) {
+			continue
+		}
+		link.Marketplace = r.marketplace
+		link.ProductID = r.productID(u)
+		break
+	}
+	return link, nil
+}
+
+func (r rule) productID(u *url.URL) string {
+	query := u.Query()
+	for _, param := range r.params {
+		if value := query.Get(param); isDigits(value) {
+			return value
+		}
+	}
+	for _, path := range r.paths {
+		if match := path.FindStringSubmatch(u.Path); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
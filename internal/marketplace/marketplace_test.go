@@ -0,0 +1,64 @@
+package marketplace
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		url         string
+		marketplace string
+		productID   string
+	}{
+		{"https://detail.1688.com/offer/652345678901.html?spm=a26352", Alibaba1688, "652345678901"},
+		{"https://m.1688.com/offer/652345678901.html", Alibaba1688, "652345678901"},
+		{"https://item.taobao.com/item.htm?spm=a1z10&id=712345678901", Taobao, "712345678901"},
+		{"https://detail.tmall.com/item.htm?id=612345678901", Taobao, "612345678901"},
+		{"https://world.taobao.com/item/612345678901.htm", Taobao, "612345678901"},
+		{"https://m.tb.cn/h.5Xyz", Taobao, ""},
+		{"https://www.alibaba.com/product-detail/LED-Lamp-Wholesale_1600123456789.html", Alibaba, "1600123456789"},
+		{"https://m.alibaba.com/product/1600123456789/LED-Lamp.html", Alibaba, "1600123456789"},
+		{"https://mobile.yangkeduo.com/goods.html?goods_id=412345678", Pinduoduo, "412345678"},
+		{"https://www.ozon.ru/product/nastolnaya-lampa-led-1234567890/?asb=1", Ozon, "1234567890"},
+		{"https://ozon.ru/product/1234567890", Ozon, "1234567890"},
+		{"https://www.wildberries.ru/catalog/123456789/detail.aspx?targetUrl=GP", Wildberries, "123456789"},
+		{"wildberries.ru/catalog/123456789/detail.aspx", Wildberries, "123456789"},
+		{"https://www.wildberries.ru/catalog/elektronika", Wildberries, ""},
+		{"https://example.com/lamp", Other, ""},
+	}
+
+	for _, tt := range tests {
+		link, err := Parse(tt.url)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.url, err)
+			continue
+		}
+		if link.Marketplace != tt.marketplace || link.ProductID != tt.productID {
+			t.Errorf("Parse(%q) = %s %q, ожидалось %s %q", tt.url, link.Marketplace, link.ProductID, tt.marketplace, tt.productID)
+		}
+	}
+
+	for _, raw := range []string{"ftp://files.1688.com/a", "javascript://alert(1)", "https://localhost/a", "http://"} {
+		if _, err := Parse(raw); err != ErrInvalidURL {
+			t.Errorf("Parse(%q): ожидалась ошибка, получено %v", raw, err)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	text := "Вот ссылки: https://detail.1688.com/offer/111.html, https://detail.1688.com/offer/111.html?spm=x\n" +
+		"【淘宝】https://item.taobao.com/item.htm?id=222】 и ozon.ru/product/lampa-333/.\n" +
+		"ещё ftp://example.com/file и просто текст"
+
+	links, invalid := Extract(text)
+	if len(links) != 3 {
+		t.Fatalf("Ожидалось три ссылки, получено %+v", links)
+	}
+	want := []string{"111", "222", "333"}
+	for i, link := range links {
+		if link.ProductID != want[i] {
+			t.Errorf("Ссылка %d: ожидался товар %s, получено %+v", i, want[i], link)
+		}
+	}
+	if len(invalid) != 1 || invalid[0] != "ftp://example.com/file" {
+		t.Errorf("Ожидалась одна недопустимая ссылка, получено %v", invalid)
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Attachment - файл, приложенный к заявке. Содержимое лежит в хранилище
+// файлов под ключом StorageKey
+type Attachment struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	IssueID    uint   `json:"issueId" gorm:"not null;index"`
+	FileName   string `json:"fileName" gorm:"not null"`
+	Size       int64  `json:"size" gorm:"not null"`
+	MimeType   string `json:"mimeType" gorm:"not null"`
+	Checksum   string `json:"checksum" gorm:"not null"`
+	StorageKey string `json:"-"`
+	// Превью изображения в JPEG, пусто для остальных файлов
+	ThumbnailKey string `json:"-"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	// Подозрительные файлы помещаются в карантин и не отдаются до проверки менеджером
+	Quarantined      bool      `json:"quarantined" gorm:"not null;default:false"`
+	QuarantineReason string    `json:"quarantineReason,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// FileUpload - загруженный файл до сохранения во вложения заявки
+type FileUpload struct {
+	FileName string
+	Data     []byte
+}
+
+// AttachmentView - вложение в ответе API со ссылкой на скачивание
+type AttachmentView struct {
+	ID               uint      `json:"id"`
+	FileName         string    `json:"fileName"`
+	Size             int64     `json:"size"`
+	MimeType         string    `json:"mimeType"`
+	Checksum         string    `json:"checksum"`
+	URL              string    `json:"url"`
+	ThumbnailURL     string    `json:"thumbnailUrl,omitempty"`
+	Width            int       `json:"width,omitempty"`
+	Height           int       `json:"height,omitempty"`
+	Quarantined      bool      `json:"quarantined,omitempty"`
+	QuarantineReason string    `json:"quarantineReason,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
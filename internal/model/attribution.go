@@ -0,0 +1,16 @@
+package model
+
+// DefaultLeadSource - источник заявки, если он не передан и не определяется по меткам
+const DefaultLeadSource = "Сайт"
+
+// Attribution - источник заявки: откуда пришел клиент и с какими UTM-метками
+type Attribution struct {
+	Source      string `json:"source" gorm:"index"`
+	LandingPage string `json:"landingPage,omitempty"`
+	Referrer    string `json:"referrer,omitempty"`
+	UTMSource   string `json:"utmSource,omitempty"`
+	UTMMedium   string `json:"utmMedium,omitempty"`
+	UTMCampaign string `json:"utmCampaign,omitempty" gorm:"index"`
+	UTMTerm     string `json:"utmTerm,omitempty"`
+	UTMContent  string `json:"utmContent,omitempty"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Действия журнала изменений
+const (
+	AuditActionUpdated  = "updated"
+	AuditActionDeleted  = "deleted"
+	AuditActionRestored = "restored"
+	AuditActionPurged   = "purged"
+	// Заявка-источник объединена с другой заявкой, NewValue - ID итоговой заявки
+	AuditActionMergedInto = "merged_into"
+	// В заявку объединена другая заявка, OldValue - ID заявки-источника
+	AuditActionMerged = "merged"
+	// Добавлена или удалена связь с другой заявкой: Field - тип связи,
+	// NewValue или OldValue - ID второй заявки
+	AuditActionLinked   = "linked"
+	AuditActionUnlinked = "unlinked"
+	// Менеджер подтвердил данные инвойса, NewValue - заявленная стоимость
+	AuditActionInvoiceConfirmed = "invoice_confirmed"
+)
+
+// AuditEntry - запись журнала изменений заявки. Для изменений полей
+// заполняются Field, OldValue и NewValue. Записи не удаляются вместе
+// с заявкой, чтобы история сохранялась и после окончательного удаления.
+type AuditEntry struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	IssueID   uint      `json:"issueId" gorm:"not null;index"`
+	Action    string    `json:"action" gorm:"not null"`
+	Field     string    `json:"field,omitempty"`
+	OldValue  string    `json:"oldValue,omitempty"`
+	NewValue  string    `json:"newValue,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Customer - клиент, оставивший одну или несколько заявок.
+// Заявки клиента связываются по нормализованному контакту.
+type Customer struct {
+	ID                     uint      `json:"id" gorm:"primaryKey"`
+	FullName               string    `json:"fullName" gorm:"not null"`
+	ContactInfo            string    `json:"contactInfo" gorm:"not null"`
+	ContactKey             string    `json:"-" gorm:"uniqueIndex;not null"`
+	PreferredContactMethod string    `json:"preferredContactMethod"`
+	HasChinaExperience     bool      `json:"hasChinaExperience" gorm:"not null"`
+	HasSupplierContacts    bool      `json:"hasSupplierContacts" gorm:"not null"`
+	CreatedAt              time.Time `json:"createdAt"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+// CustomerStats - показатели клиента за все время. Объем, вес и выручка
+// считаются по успешно закрытым заявкам.
+type CustomerStats struct {
+	IssuesCount int64   `json:"issuesCount"`
+	WonCount    int64   `json:"wonCount"`
+	Volume      float64 `json:"volume"`
+	Weight      float64 `json:"weight"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// CustomerCard - карточка клиента
+type CustomerCard struct {
+	Customer
+	Stats CustomerStats `json:"stats"`
+}
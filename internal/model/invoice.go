@@ -0,0 +1,47 @@
+package model
+
+// Статусы данных инвойса заявки
+const (
+	// Данные извлечены из загруженного файла и ждут проверки менеджером
+	InvoiceStatusExtracted = "extracted"
+	// Данные проверены менеджером и больше не перезаписываются из файлов
+	InvoiceStatusConfirmed = "confirmed"
+)
+
+// DeclaredInvoice - сведения из инвойса поставщика: поставщик, валюта
+// и заявленная стоимость товара
+type DeclaredInvoice struct {
+	SupplierName  string   `json:"supplierName,omitempty"`
+	Currency      string   `json:"currency,omitempty"`
+	DeclaredValue *float64 `json:"declaredValue,omitempty"`
+	InvoiceStatus string   `json:"invoiceStatus,omitempty"`
+}
+
+// LineItem - позиция инвойса заявки
+type LineItem struct {
+	ID          uint     `json:"id" gorm:"primaryKey"`
+	IssueID     uint     `json:"-" gorm:"not null;index"`
+	Position    int      `json:"position" gorm:"not null"`
+	Description string   `json:"description" gorm:"not null"`
+	Quantity    *float64 `json:"quantity,omitempty"`
+	UnitPrice   *float64 `json:"unitPrice,omitempty"`
+	Amount      *float64 `json:"amount,omitempty"`
+}
+
+// InvoiceFields - данные инвойса, которые менеджер проверяет и исправляет
+// перед подтверждением. К ним применяется JSON Merge Patch, позиции
+// заменяются целиком
+type InvoiceFields struct {
+	SupplierName  string           `json:"supplierName"`
+	Currency      string           `json:"currency" binding:"omitempty,len=3"`
+	DeclaredValue *float64         `json:"declaredValue" binding:"omitempty,gte=0"`
+	LineItems     []LineItemFields `json:"lineItems" binding:"dive"`
+}
+
+// LineItemFields - редактируемые поля позиции инвойса
+type LineItemFields struct {
+	Description string   `json:"description" binding:"required"`
+	Quantity    *float64 `json:"quantity" binding:"omitempty,gte=0"`
+	UnitPrice   *float64 `json:"unitPrice" binding:"omitempty,gte=0"`
+	Amount      *float64 `json:"amount" binding:"omitempty,gte=0"`
+}
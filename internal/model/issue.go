@@ -6,24 +6,67 @@ import (
 	"gorm.io/gorm"
 )
 
+// Статусы заявки
+const (
+	StatusOpen      = "open"
+	StatusContacted = "contacted"
+	StatusQuoted    = "quoted"
+	StatusClosed    = "closed"
+	StatusLost      = "lost"
+)
+
+// Способы связи с клиентом
+const (
+	ContactMethodPhone    = "phone"
+	ContactMethodTelegram = "telegram"
+	ContactMethodWhatsApp = "whatsapp"
+	ContactMethodEmail    = "email"
+	ContactMethodWeChat   = "wechat"
+)
+
 type Issue struct {
-	ID                     uint           `json:"id" gorm:"primaryKey"`
-	FullName               string         `json:"fullName" gorm:"not null"`
-	ContactInfo            string         `json:"contactInfo" gorm:"not null"`
-	PreferredContactMethod string         `json:"preferredContactMethod" gorm:"not null"`
-	HasChinaExperience     bool           `json:"hasChinaExperience" gorm:"not null"`
-	HasSupplierContacts    bool           `json:"hasSupplierContacts" gorm:"not null"`
-	ProductDescription     string         `json:"productDescription" gorm:"not null"`
-	ExistingProductLinks   string         `json:"existingProductLinks"`
-	Volume                 *float64       `json:"volume,omitempty"`
-	Weight                 *float64       `json:"weight,omitempty"`
-	Density                *float64       `json:"density,omitempty"`
-	PreviousInvoiceFile    string         `json:"previousInvoiceFile,omitempty"`
-	ExpectedDeliveryDate   string         `json:"expectedDeliveryDate" gorm:"not null"`
-	Status                 string         `json:"status" gorm:"default:'open'"`
-	CreatedAt              time.Time      `json:"createdAt"`
-	UpdatedAt              time.Time      `json:"updatedAt"`
-	DeletedAt              gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                     uint          `json:"id" gorm:"primaryKey"`
+	FullName               string        `json:"fullName" gorm:"not null"`
+	ContactInfo            string        `json:"contactInfo" gorm:"not null"`
+	PreferredContactMethod string        `json:"preferredContactMethod" gorm:"not null"`
+	HasChinaExperience     bool          `json:"hasChinaExperience" gorm:"not null"`
+	HasSupplierContacts    bool          `json:"hasSupplierContacts" gorm:"not null"`
+	ProductDescription     string        `json:"productDescription" gorm:"not null"`
+	ExistingProductLinks   string        `json:"existingProductLinks"`
+	Volume                 *float64      `json:"volume,omitempty"`
+	Weight                 *float64      `json:"weight,omitempty"`
+	Density                *float64      `json:"density,omitempty"`
+	PreviousInvoiceFile    string        `json:"previousInvoiceFile,omitempty"`
+	ExpectedDeliveryDate   string        `json:"expectedDeliveryDate" gorm:"not null"`
+	Status                 string        `json:"status" gorm:"default:'open';index"`
+	Assignee               string        `json:"assignee" gorm:"index"`
+	FirstContactAt         *time.Time    `json:"firstContactAt,omitempty"`
+	QuotedAt               *time.Time    `json:"quotedAt,omitempty"`
+	FirstContactEscalated  bool          `json:"-" gorm:"not null;default:false"`
+	QuoteEscalated         bool          `json:"-" gorm:"not null;default:false"`
+	Tags                   []Tag         `json:"tags" gorm:"many2many:issue_tags;"`
+	Links                  []IssueLink   `json:"-" gorm:"foreignKey:IssueID"`
+	BackLinks              []IssueLink   `json:"-" gorm:"foreignKey:LinkedIssueID"`
+	Attachments            []Attachment  `json:"-" gorm:"foreignKey:IssueID"`
+	LineItems              []LineItem    `json:"-" gorm:"foreignKey:IssueID"`
+	ProductLinks           []ProductLink `json:"-" gorm:"foreignKey:IssueID"`
+	Version                uint          `json:"version" gorm:"not null;default:1"`
+	ContactKey             string        `json:"-" gorm:"index"`
+	DuplicateOfID          *uint         `json:"duplicateOfId,omitempty" gorm:"index"`
+	MergedIntoID           *uint         `json:"mergedIntoId,omitempty" gorm:"index"`
+	ClonedFromID           *uint         `json:"clonedFromId,omitempty" gorm:"index"`
+	CustomerID             *uint         `json:"customerId,omitempty" gorm:"index"`
+	QuoteAmount            *float64      `json:"quoteAmount,omitempty"`
+	LossReason             string        `json:"lossReason,omitempty" gorm:"index"`
+	LossNote               string        `json:"lossNote,omitempty"`
+	LostAt                 *time.Time    `json:"lostAt,omitempty"`
+	Score                  int           `json:"score" gorm:"not null;default:0;index"`
+	ScoreFactors           string        `json:"-" gorm:"type:text"`
+	DeclaredInvoice
+	Attribution
+	CreatedAt time.Time      `json:"createdAt" gorm:"index"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 type CreateIssueRequest struct {
@@ -39,27 +82,138 @@ type CreateIssueRequest struct {
 	Density                *float64 `json:"density,omitempty"`
 	PreviousInvoiceFile    string   `json:"previousInvoiceFile,omitempty"`
 	ExpectedDeliveryDate   string   `json:"expectedDeliveryDate" binding:"required"`
+	Attribution
+}
+
+// IssueFields - редактируемые поля заявки. К ним применяется JSON Merge Patch
+// (RFC 7396), после чего они проверяются так же, как при создании заявки.
+type IssueFields struct {
+	FullName               string   `json:"fullName" binding:"required"`
+	ContactInfo            string   `json:"contactInfo" binding:"required"`
+	PreferredContactMethod string   `json:"preferredContactMethod" binding:"required"`
+	HasChinaExperience     bool     `json:"hasChinaExperience"`
+	HasSupplierContacts    bool     `json:"hasSupplierContacts"`
+	ProductDescription     string   `json:"productDescription" binding:"required"`
+	ExistingProductLinks   string   `json:"existingProductLinks"`
+	Volume                 *float64 `json:"volume"`
+	Weight                 *float64 `json:"weight"`
+	Density                *float64 `json:"density"`
+	PreviousInvoiceFile    string   `json:"previousInvoiceFile"`
+	ExpectedDeliveryDate   string   `json:"expectedDeliveryDate" binding:"required"`
+	Status                 string   `json:"status" binding:"required,oneof=open contacted quoted closed lost"`
+	Assignee               string   `json:"assignee"`
+	QuoteAmount            *float64 `json:"quoteAmount" binding:"omitempty,gte=0"`
+	// LossReason обязательна для статуса lost и сбрасывается при выходе из него
+	LossReason string `json:"lossReason"`
+	LossNote   string `json:"lossNote"`
 }
 
 type UpdateIssueRequest struct {
-	Status string `json:"status" binding:"required,oneof=open closed"`
+	Status     string `json:"status" binding:"required,oneof=open contacted quoted closed lost"`
+	LossReason string `json:"lossReason,omitempty"`
+	LossNote   string `json:"lossNote,omitempty"`
 }
 
 type IssueResponse struct {
-	ID                     uint      `json:"id"`
-	FullName               string    `json:"fullName"`
-	ContactInfo            string    `json:"contactInfo"`
-	PreferredContactMethod string    `json:"preferredContactMethod"`
-	HasChinaExperience     bool      `json:"hasChinaExperience"`
-	HasSupplierContacts    bool      `json:"hasSupplierContacts"`
-	ProductDescription     string    `json:"productDescription"`
-	ExistingProductLinks   string    `json:"existingProductLinks"`
-	Volume                 *float64  `json:"volume,omitempty"`
-	Weight                 *float64  `json:"weight,omitempty"`
-	Density                *float64  `json:"density,omitempty"`
-	PreviousInvoiceFile    string    `json:"previousInvoiceFile,omitempty"`
-	ExpectedDeliveryDate   string    `json:"expectedDeliveryDate"`
-	Status                 string    `json:"status"`
-	CreatedAt              time.Time `json:"createdAt"`
-	UpdatedAt              time.Time `json:"updatedAt"`
+	ID                     uint             `json:"id"`
+	FullName               string           `json:"fullName"`
+	ContactInfo            string           `json:"contactInfo"`
+	PreferredContactMethod string           `json:"preferredContactMethod"`
+	HasChinaExperience     bool             `json:"hasChinaExperience"`
+	HasSupplierContacts    bool             `json:"hasSupplierContacts"`
+	ProductDescription     string           `json:"productDescription"`
+	ExistingProductLinks   string           `json:"existingProductLinks"`
+	Volume                 *float64         `json:"volume,omitempty"`
+	Weight                 *float64         `json:"weight,omitempty"`
+	Density                *float64         `json:"density,omitempty"`
+	PreviousInvoiceFile    string           `json:"previousInvoiceFile,omitempty"`
+	ExpectedDeliveryDate   string           `json:"expectedDeliveryDate"`
+	Status                 string           `json:"status"`
+	Assignee               string           `json:"assignee"`
+	SLA                    SLAState         `json:"sla"`
+	Tags                   []string         `json:"tags"`
+	Links                  []IssueLinkView  `json:"links"`
+	Attachments            []AttachmentView `json:"attachments"`
+	LineItems              []LineItem       `json:"lineItems"`
+	ProductLinks           []ProductLink    `json:"productLinks"`
+	Version                uint             `json:"version"`
+	DuplicateOfID          *uint            `json:"duplicateOfId,omitempty"`
+	MergedIntoID           *uint            `json:"mergedIntoId,omitempty"`
+	ClonedFromID           *uint            `json:"clonedFromId,omitempty"`
+	CustomerID             *uint            `json:"customerId,omitempty"`
+	QuoteAmount            *float64         `json:"quoteAmount,omitempty"`
+	LossReason             string           `json:"lossReason,omitempty"`
+	LossNote               string           `json:"lossNote,omitempty"`
+	LostAt                 *time.Time       `json:"lostAt,omitempty"`
+	Score                  int              `json:"score"`
+	ScoreFactors           []ScoreFactor    `json:"scoreFactors"`
+	DeclaredInvoice
+	Attribution
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// IssueFilter описывает условия выборки списка заявок
+type IssueFilter struct {
+	Tags         []string
+	MatchAllTags bool
+	Statuses     []string
+	// Assignee - менеджер заявки, IssueAssigneeNone - заявки без менеджера
+	Assignee           string
+	HasChinaExperience *bool
+	// Created - период создания заявки
+	Created ReportPeriod
+	// SortBy - поле сортировки (IssueSort*), по умолчанию дата создания.
+	// SortAsc меняет порядок на возрастающий
+	SortBy  string
+	SortAsc bool
+	// Page начинается с 1
+	Page  int
+	Limit int
+	// Cursor - непрозрачный курсор страницы из ссылок next/prev,
+	// используется вместо Page при сортировке по дате создания
+	Cursor string
+}
+
+// IssueCursor - позиция в списке заявок, отсортированном по дате создания.
+// Before выбирает страницу перед позицией, иначе - после нее
+type IssueCursor struct {
+	CreatedAt time.Time
+	ID        uint
+	Before    bool
+}
+
+// Поля сортировки списка заявок
+const (
+	IssueSortCreatedAt = "createdAt"
+	IssueSortUpdatedAt = "updatedAt"
+	IssueSortScore     = "score"
+	IssueSortFullName  = "fullName"
+	IssueSortStatus    = "status"
+)
+
+// IssueAssigneeNone в фильтре по менеджеру выбирает заявки без менеджера
+const IssueAssigneeNone = "none"
+
+// Размер страницы списка заявок
+const (
+	DefaultIssueLimit = 50
+	MaxIssueLimit     = 200
+)
+
+// IssueStatuses - все статусы заявки
+var IssueStatuses = []string{StatusOpen, StatusContacted, StatusQuoted, StatusClosed, StatusLost}
+
+// IssuePage - страница списка заявок и общее количество заявок,
+// подходящих под фильтр
+type IssuePage struct {
+	Items []IssueResponse
+	Total int64
+	Page  int
+	Limit int
+	// NextCursor и PrevCursor - курсоры соседних страниц, пустые, если
+	// страницы нет или список отсортирован не по дате создания
+	NextCursor string
+	PrevCursor string
 }
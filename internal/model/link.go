@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Типы связей между заявками. Связь направлена от заявки IssueID
+// к заявке LinkedIssueID: "заявка IssueID - повтор заявки LinkedIssueID"
+const (
+	LinkTypeRepeatOf         = "repeat-of"
+	LinkTypeDuplicateOf      = "duplicate-of"
+	LinkTypeSplitFrom        = "split-from"
+	LinkTypeConsolidatedWith = "consolidated-with"
+)
+
+// Направление связи относительно заявки, в которой она показывается
+const (
+	LinkDirectionOutgoing = "outgoing"
+	LinkDirectionIncoming = "incoming"
+)
+
+type IssueLink struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	IssueID       uint      `json:"issueId" gorm:"not null;index"`
+	LinkedIssueID uint      `json:"linkedIssueId" gorm:"not null;index"`
+	Type          string    `json:"type" gorm:"not null"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type CreateIssueLinkRequest struct {
+	Type    string `json:"type" binding:"required,oneof=repeat-of duplicate-of split-from consolidated-with"`
+	IssueID uint   `json:"issueId" binding:"required"`
+}
+
+// IssueLinkView - связь в ответе API с ID второй заявки
+type IssueLinkView struct {
+	ID        uint   `json:"id"`
+	Type      string `json:"type"`
+	IssueID   uint   `json:"issueId"`
+	Direction string `json:"direction"`
+}
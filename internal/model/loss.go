@@ -0,0 +1,22 @@
+package model
+
+// PredefinedLossReasons создаются при миграции и доступны при закрытии любой заявки
+var PredefinedLossReasons = []LossReason{
+	{Code: "price", Name: "Цена"},
+	{Code: "timing", Name: "Сроки"},
+	{Code: "competitor", Name: "Ушел к конкуренту"},
+	{Code: "no_response", Name: "Нет ответа"},
+	{Code: "restricted_goods", Name: "Запрещенный товар"},
+}
+
+// LossReason - причина проигрыша заявки из справочника
+type LossReason struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Code string `json:"code" gorm:"uniqueIndex;not null"`
+	Name string `json:"name" gorm:"not null"`
+}
+
+type CreateLossReasonRequest struct {
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
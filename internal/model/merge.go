@@ -0,0 +1,15 @@
+package model
+
+import "encoding/json"
+
+type MergeIssuesRequest struct {
+	SourceIDs []uint `json:"sourceIds" binding:"required,min=1"`
+	// Явный выбор значений при конфликте: поле -> ID заявки, из которой взять значение
+	Fields map[string]uint `json:"fields"`
+}
+
+// MergeCandidate - значение поля в одной из объединяемых заявок
+type MergeCandidate struct {
+	IssueID uint            `json:"issueId"`
+	Value   json.RawMessage `json:"value"`
+}
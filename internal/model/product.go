@@ -0,0 +1,22 @@
+package model
+
+// ProductLink - ссылка на товар из поля ExistingProductLinks заявки.
+// Marketplace - код маркетплейса (1688, taobao, alibaba, pinduoduo, ozon,
+// wildberries или other), ProductID - идентификатор товара на нем
+type ProductLink struct {
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	IssueID     uint   `json:"-" gorm:"not null;index"`
+	Position    int    `json:"-" gorm:"not null"`
+	URL         string `json:"url" gorm:"not null"`
+	Marketplace string `json:"marketplace" gorm:"not null;index:idx_product_links_product"`
+	ProductID   string `json:"productId,omitempty" gorm:"index:idx_product_links_product"`
+}
+
+// ProductGroup - товар, на который ссылаются заявки
+type ProductGroup struct {
+	Marketplace string `json:"marketplace"`
+	ProductID   string `json:"productId"`
+	URL         string `json:"url"`
+	IssueCount  int    `json:"issueCount"`
+	LastIssueID uint   `json:"lastIssueId"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Reminder - напоминание перезвонить клиенту по заявке
+type Reminder struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	IssueID   uint       `json:"issueId" gorm:"not null;index"`
+	RemindAt  time.Time  `json:"remindAt" gorm:"not null;index"`
+	Note      string     `json:"note"`
+	DoneAt    *time.Time `json:"doneAt,omitempty" gorm:"index"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+type CreateReminderRequest struct {
+	RemindAt time.Time `json:"remindAt" binding:"required"`
+	Note     string    `json:"note"`
+}
+
+type AssignIssueRequest struct {
+	Assignee string `json:"assignee"`
+}
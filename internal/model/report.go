@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// ReportPeriod - период отчета, To не включается
+type ReportPeriod struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// SourceReportRow - заявки и конверсия по источнику и рекламной кампании
+type SourceReportRow struct {
+	Source         string  `json:"source"`
+	Campaign       string  `json:"campaign"`
+	Leads          int64   `json:"leads"`
+	Conversions    int64   `json:"conversions"`
+	ConversionRate float64 `json:"conversionRate"`
+	Revenue        float64 `json:"revenue"`
+}
+
+// Интервалы группировки отчетов по времени
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// LossReasonReportRow - количество проигранных заявок по причине за интервал
+type LossReasonReportRow struct {
+	Period string `json:"period"`
+	Reason string `json:"reason"`
+	Name   string `json:"name"`
+	Count  int64  `json:"count"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// ScoreFactor - правило оценки заявки, которое сработало, и его вклад в оценку
+type ScoreFactor struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	Points      int    `json:"points"`
+}
@@ -0,0 +1,28 @@
+package model
+
+// Поля заявки в полнотекстовом индексе в порядке столбцов индекса.
+// Заметки - примечание к проигрышу и заметки напоминаний
+var IssueSearchFields = []string{"fullName", "contactInfo", "productDescription", "existingProductLinks", "notes"}
+
+// Размер выдачи поиска по заявкам
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// IssueSearchHit - совпадение в полнотекстовом индексе. Snippets - фрагменты
+// полей в порядке IssueSearchFields, совпадения выделены разметкой
+type IssueSearchHit struct {
+	IssueID  uint
+	Rank     float64
+	Snippets []string
+}
+
+// IssueSearchResult - заявка в результатах поиска. Rank - релевантность,
+// чем больше, тем выше заявка в выдаче. Highlights - фрагменты полей,
+// в которых нашлись слова запроса
+type IssueSearchResult struct {
+	IssueResponse
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
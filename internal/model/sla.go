@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Состояния SLA-показателя
+const (
+	SLAStatusOnTrack   = "on_track"
+	SLAStatusAtRisk    = "at_risk"
+	SLAStatusBreached  = "breached"
+	SLAStatusMet       = "met"
+	SLAStatusMissed    = "missed"
+	SLAStatusCancelled = "cancelled"
+)
+
+// SLA-показатели заявки
+const (
+	SLAMetricFirstContact = "firstContact"
+	SLAMetricQuote        = "quote"
+)
+
+// SLAMetric - состояние одного показателя SLA. Время считается только
+// в рабочие часы согласно календарю из конфигурации.
+type SLAMetric struct {
+	Status         string     `json:"status"`
+	TargetMinutes  float64    `json:"targetMinutes"`
+	ElapsedMinutes float64    `json:"elapsedMinutes"`
+	Deadline       time.Time  `json:"deadline"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
+
+type SLAState struct {
+	FirstContact SLAMetric `json:"firstContact"`
+	Quote        SLAMetric `json:"quote"`
+}
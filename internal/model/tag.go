@@ -0,0 +1,18 @@
+package model
+
+// PredefinedTags создаются при миграции и доступны во всех заявках
+var PredefinedTags = []string{
+	"постоянный клиент",
+	"электроника",
+	"срочно",
+}
+
+type Tag struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Name       string `json:"name" gorm:"uniqueIndex;not null"`
+	Predefined bool   `json:"predefined" gorm:"not null;default:false"`
+}
+
+type IssueTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1,dive,required"`
+}
@@ -0,0 +1,188 @@
+package notifier
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"calc_example/internal/config"
+	"calc_example/internal/model"
+	"calc_example/pkg/telegram"
+)
+
+// Notifier формирует уведомления о заявках и отправляет их менеджерам в Telegram
+type Notifier struct {
+	client   *telegram.Client
+	frontend config.FrontendConfig
+	// Внешний адрес API для ссылок на превью фотографий
+	api string
+}
+
+func New(client *telegram.Client, frontend config.FrontendConfig, api string) *Notifier {
+	return &Notifier{
+		client:   client,
+		frontend: frontend,
+		api:      api,
+	}
+}
+
+// IssueLink возвращает ссылку на заявку во фронтенде
+func (n *Notifier) IssueLink(id uint) string {
+	return fmt.Sprintf("%s:%s/#/issues/%d", n.frontend.Url, n.frontend.Port, id)
+}
+
+func (n *Notifier) NewIssue(issue *model.IssueResponse) error {
+	message := fmt.Sprintf("🆕 <b>Новая заявка</b>\n\n"+
+		"👤 Имя: %s\n"+
+		"📞 Телефон: %s\n\n"+
+		"📦 Товар: %s\n"+
+		"%s"+
+		"📲 Источник: %s\n"+
+		"⭐ Оценка: %s\n\n"+
+		"🧑🏻‍💻 Менеджер: %s\n"+
+		"📌 Статус: %s\n\n"+
+		"🔗 <a href=\"%s\">Открыть заявку!</a>",
+		html.EscapeString(issue.FullName),
+		html.EscapeString(issue.ContactInfo),
+		html.EscapeString(issue.ProductDescription),
+		n.photosLine(issue),
+		html.EscapeString(sourceLabel(issue)),
+		html.EscapeString(scoreLabel(issue)),
+		"Виртуальный помощник",
+		"Ожидает ответа",
+		n.IssueLink(issue.ID),
+	)
+
+	return n.client.SendMessage(message)
+}
+
+// DuplicateIssue сообщает о повторной заявке клиента со ссылкой на исходную
+// вместо отдельного оповещения о новой заявке
+func (n *Notifier) DuplicateIssue(issue *model.IssueResponse) error {
+	message := fmt.Sprintf("🔁 <b>Повторная заявка</b>\n\n"+
+		"👤 Имя: %s\n"+
+		"📞 Телефон: %s\n\n"+
+		"📦 Товар: %s\n"+
+		"%s\n"+
+		"Похоже, клиент уже оставлял <a href=\"%s\">заявку #%d</a>.\n\n"+
+		"🔗 <a href=\"%s\">Открыть новую заявку</a>",
+		html.EscapeString(issue.FullName),
+		html.EscapeString(issue.ContactInfo),
+		html.EscapeString(issue.ProductDescription),
+		n.photosLine(issue),
+		n.IssueLink(*issue.DuplicateOfID),
+		*issue.DuplicateOfID,
+		n.IssueLink(issue.ID),
+	)
+
+	return n.client.SendMessage(message)
+}
+
+func (n *Notifier) Reminder(issue *model.IssueResponse, reminder *model.Reminder) error {
+	message := fmt.Sprintf("⏰ <b>Напоминание: перезвонить клиенту</b>\n\n"+
+		"👤 Имя: %s\n"+
+		"📞 Контакт: %s\n"+
+		"📦 Товар: %s\n\n"+
+		"📝 Комментарий: %s\n\n"+
+		"🧑🏻‍💻 Менеджер: %s\n\n"+
+		"🔗 <a href=\"%s\">Открыть заявку!</a>",
+		html.EscapeString(issue.FullName),
+		html.EscapeString(issue.ContactInfo),
+		html.EscapeString(issue.ProductDescription),
+		orDash(html.EscapeString(reminder.Note)),
+		assigneeOrDefault(issue.Assignee),
+		n.IssueLink(issue.ID),
+	)
+
+	return n.client.SendMessage(message)
+}
+
+func (n *Notifier) SLAEscalation(issue *model.IssueResponse, metric string) error {
+	title, state := "первый контакт", issue.SLA.FirstContact
+	if metric == model.SLAMetricQuote {
+		title, state = "отправка расчета", issue.SLA.Quote
+	}
+
+	headline := "⚠️ <b>SLA под угрозой</b>"
+	if state.Status == model.SLAStatusBreached {
+		headline = "🚨 <b>SLA нарушен</b>"
+	}
+
+	message := fmt.Sprintf("%s: %s\n\n"+
+		"👤 Имя: %s\n"+
+		"📞 Контакт: %s\n"+
+		"📦 Товар: %s\n\n"+
+		"⏱ Прошло: %.0f из %.0f мин. рабочего времени\n"+
+		"📅 Срок: %s\n\n"+
+		"🧑🏻‍💻 Менеджер: %s\n\n"+
+		"🔗 <a href=\"%s\">Открыть заявку!</a>",
+		headline,
+		title,
+		html.EscapeString(issue.FullName),
+		html.EscapeString(issue.ContactInfo),
+		html.EscapeString(issue.ProductDescription),
+		state.ElapsedMinutes,
+		state.TargetMinutes,
+		state.Deadline.Format("02.01.2006 15:04"),
+		assigneeOrDefault(issue.Assignee),
+		n.IssueLink(issue.ID),
+	)
+
+	return n.client.SendMessage(message)
+}
+
+// sourceLabel возвращает источник заявки вместе с рекламной кампанией
+func sourceLabel(issue *model.IssueResponse) string {
+	if issue.Source == "" {
+		return model.DefaultLeadSource
+	}
+	if issue.UTMCampaign != "" {
+		return fmt.Sprintf("%s (%s)", issue.Source, issue.UTMCampaign)
+	}
+	return issue.Source
+}
+
+// scoreLabel показывает оценку заявки вместе со сработавшими правилами
+func scoreLabel(issue *model.IssueResponse) string {
+	if len(issue.ScoreFactors) == 0 {
+		return strconv.Itoa(issue.Score)
+	}
+
+	reasons := make([]string, 0, len(issue.ScoreFactors))
+	for _, factor := range issue.ScoreFactors {
+		reasons = append(reasons, fmt.Sprintf("%s %+d", factor.Description, factor.Points))
+	}
+	return fmt.Sprintf("%d (%s)", issue.Score, strings.Join(reasons, "; "))
+}
+
+// photosLine возвращает строку со ссылками на превью фотографий заявки.
+// Telegram показывает предпросмотр первой ссылки сообщения, поэтому
+// менеджер сразу видит первое фото
+func (n *Notifier) photosLine(issue *model.IssueResponse) string {
+	var links []string
+	for _, attachment := range issue.Attachments {
+		if attachment.ThumbnailURL == "" || attachment.Quarantined {
+			continue
+		}
+		links = append(links, fmt.Sprintf("<a href=\"%s%s\">%d</a>", n.api, attachment.ThumbnailURL, len(links)+1))
+	}
+	if len(links) == 0 {
+		return ""
+	}
+	return "🖼 Фото: " + strings.Join(links, ", ") + "\n"
+}
+
+func assigneeOrDefault(assignee string) string {
+	if assignee == "" {
+		return "не назначен"
+	}
+	return html.EscapeString(assignee)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
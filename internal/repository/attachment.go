@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+)
+
+// Attachment Repository
+func (r *Repository) CreateAttachments(attachments []model.Attachment) error {
+	return r.db.Create(&attachments).Error
+}
+
+// GetAttachmentByID возвращает вложение вместе с содержимым
+func (r *Repository) GetAttachmentByID(id uint) (*model.Attachment, error) {
+	var attachment model.Attachment
+	err := r.db.First(&attachment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetIssueAttachmentsSize возвращает общий размер вложений заявки
+func (r *Repository) GetIssueAttachmentsSize(issueID uint) (int64, error) {
+	var size int64
+	err := r.db.Model(&model.Attachment{}).
+		Where("issue_id = ?", issueID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&size).Error
+	return size, err
+}
+
+func (r *Repository) ReleaseAttachment(id uint) error {
+	return r.db.Model(&model.Attachment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"quarantined": false, "quarantine_reason": ""}).Error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+)
+
+// Audit Repository
+func (r *Repository) CreateAuditEntries(entries []model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Create(&entries).Error
+}
+
+func (r *Repository) GetIssueHistory(issueID uint) ([]model.AuditEntry, error) {
+	var entries []model.AuditEntry
+	err := r.db.Where("issue_id = ?", issueID).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Customer Repository
+func (r *Repository) GetCustomerByID(id uint) (*model.Customer, error) {
+	var customer model.Customer
+	err := r.db.First(&customer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *Repository) GetCustomerIssues(customerID uint) ([]model.Issue, error) {
+	var issues []model.Issue
+	err := withIssueRelations(r.db.DB).Where("customer_id = ?", customerID).Order("created_at DESC").Find(&issues).Error
+	return issues, err
+}
+
+func (r *Repository) GetCustomerStats(customerID uint) (*model.CustomerStats, error) {
+	var stats model.CustomerStats
+	err := r.db.Model(&model.Issue{}).
+		Select(`COUNT(*) AS issues_count,
+			COALESCE(SUM(CASE WHEN status = @won THEN 1 ELSE 0 END), 0) AS won_count,
+			COALESCE(SUM(CASE WHEN status = @won THEN volume END), 0) AS volume,
+			COALESCE(SUM(CASE WHEN status = @won THEN weight END), 0) AS weight,
+			COALESCE(SUM(CASE WHEN status = @won THEN quote_amount END), 0) AS revenue`,
+			map[string]interface{}{"won": model.StatusClosed}).
+		Where("customer_id = ?", customerID).
+		Scan(&stats).Error
+	return &stats, err
+}
+
+// GetIssuesWithoutCustomer возвращает заявки, не связанные с клиентом
+func (r *Repository) GetIssuesWithoutCustomer() ([]model.Issue, error) {
+	var issues []model.Issue
+	err := r.db.Where("customer_id IS NULL").Order("created_at").Find(&issues).Error
+	return issues, err
+}
+
+// CreateIssueWithCustomer создает заявку и связывает ее с клиентом
+// в одной транзакции (см. matchCustomer)
+func (r *Repository) CreateIssueWithCustomer(issue *model.Issue, customer *model.Customer, update func(*model.Customer)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		customerID, err := matchCustomer(tx, customer, update)
+		if err != nil {
+			return err
+		}
+		issue.CustomerID = &customerID
+		return tx.Create(issue).Error
+	})
+}
+
+// LinkIssueCustomer связывает существующую заявку с клиентом в одной
+// транзакции (см. matchCustomer) и возвращает ID клиента. Ключ контакта
+// заявки сохраняется вместе с клиентом, так как у старых заявок его нет
+func (r *Repository) LinkIssueCustomer(issueID uint, customer *model.Customer, update func(*model.Customer)) (uint, error) {
+	var customerID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		customerID, err = matchCustomer(tx, customer, update)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Issue{}).Where("id = ?", issueID).
+			UpdateColumns(map[string]interface{}{"customer_id": customerID, "contact_key": customer.ContactKey}).Error
+	})
+	return customerID, err
+}
+
+// matchCustomer создает клиента customer, если клиента с таким контактом
+// еще нет, иначе применяет update к найденному клиенту и сохраняет его.
+// Вставка пропускается при конфликте по уникальному контакту, поэтому
+// параллельные заявки одного клиента не создают второго клиента
+func matchCustomer(tx *gorm.DB, customer *model.Customer, update func(*model.Customer)) (uint, error) {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "contact_key"}},
+		DoNothing: true,
+	}).Create(customer)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return customer.ID, nil
+	}
+
+	var existing model.Customer
+	if err := tx.Where("contact_key = ?", customer.ContactKey).First(&existing).Error; err != nil {
+		return 0, err
+	}
+	update(&existing)
+	if err := tx.Save(&existing).Error; err != nil {
+		return 0, err
+	}
+	return existing.ID, nil
+}
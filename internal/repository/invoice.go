@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+	"calc_example/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// Invoice Repository
+
+// SaveInvoice сохраняет данные инвойса заявки и заменяет ее позиции
+// в одной транзакции. Заявка сохраняется с проверкой версии
+func (r *Repository) SaveInvoice(issue *model.Issue, items []model.LineItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{db: &database.Database{DB: tx}}
+		if err := txRepo.UpdateIssue(issue); err != nil {
+			return err
+		}
+
+		if err := tx.Where("issue_id = ?", issue.ID).Delete(&model.LineItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].ID = 0
+			items[i].IssueID = issue.ID
+		}
+		return tx.Create(&items).Error
+	})
+}
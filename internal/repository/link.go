@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// linkColumns - столбцы заявки, которые повторяют ее исходящие связи этих
+// типов. Столбец указывает на заявку из первой такой связи
+var linkColumns = map[string]string{
+	model.LinkTypeRepeatOf:    "cloned_from_id",
+	model.LinkTypeDuplicateOf: "duplicate_of_id",
+}
+
+// Issue Link Repository
+
+// CreateIssueLink создает связь и обновляет столбец заявки, который ее повторяет
+func (r *Repository) CreateIssueLink(link *model.IssueLink) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(link).Error; err != nil {
+			return err
+		}
+		return syncLinkColumn(tx, link)
+	})
+}
+
+func (r *Repository) GetIssueLinkByID(id uint) (*model.IssueLink, error) {
+	var link model.IssueLink
+	err := r.db.First(&link, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// IssueLinkExists проверяет, есть ли уже связь этого типа между заявками
+func (r *Repository) IssueLinkExists(link *model.IssueLink) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.IssueLink{}).
+		Where("issue_id = ? AND linked_issue_id = ? AND type = ?", link.IssueID, link.LinkedIssueID, link.Type).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteIssueLink удаляет связь и обновляет столбец заявки, который ее
+// повторял: без связей этого типа столбец очищается
+func (r *Repository) DeleteIssueLink(link *model.IssueLink) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(link).Error; err != nil {
+			return err
+		}
+		return syncLinkColumn(tx, link)
+	})
+}
+
+// syncLinkColumn записывает в столбец linkColumns заявки link.IssueID
+// первую оставшуюся связь типа link.Type
+func syncLinkColumn(tx *gorm.DB, link *model.IssueLink) error {
+	column, ok := linkColumns[link.Type]
+	if !ok {
+		return nil
+	}
+	first := tx.Model(&model.IssueLink{}).
+		Select("linked_issue_id").
+		Where("issue_id = ? AND type = ?", link.IssueID, link.Type).
+		Order("id").
+		Limit(1)
+	return tx.Model(&model.Issue{}).Where("id = ?", link.IssueID).UpdateColumn(column, first).Error
+}
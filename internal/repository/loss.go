@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+)
+
+// lossReportPeriods - форматы strftime для группировки отчета по интервалам
+var lossReportPeriods = map[string]string{
+	model.ReportIntervalDay:   "%Y-%m-%d",
+	model.ReportIntervalWeek:  "%Y-W%W",
+	model.ReportIntervalMonth: "%Y-%m",
+}
+
+// Loss Reason Repository
+func (r *Repository) GetAllLossReasons() ([]model.LossReason, error) {
+	var reasons []model.LossReason
+	err := r.db.Order("id").Find(&reasons).Error
+	return reasons, err
+}
+
+func (r *Repository) GetLossReasonByCode(code string) (*model.LossReason, error) {
+	var reason model.LossReason
+	err := r.db.Where("code = ?", code).First(&reason).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reason, nil
+}
+
+func (r *Repository) CreateLossReason(reason *model.LossReason) error {
+	return r.db.Create(reason).Error
+}
+
+// GetLossReasonReport возвращает количество проигранных заявок по причинам
+// с группировкой по дате проигрыша
+func (r *Repository) GetLossReasonReport(period model.ReportPeriod, interval string) ([]model.LossReasonReportRow, error) {
+	var rows []model.LossReasonReportRow
+
+	query := r.db.Model(&model.Issue{}).
+		Select(`strftime(?, issues.lost_at) AS period,
+			issues.loss_reason AS reason,
+			COALESCE(loss_reasons.name, issues.loss_reason) AS name,
+			COUNT(*) AS count`, lossReportPeriods[interval]).
+		Joins("LEFT JOIN loss_reasons ON loss_reasons.code = issues.loss_reason").
+		Where("issues.status = ? AND issues.lost_at IS NOT NULL", model.StatusLost)
+	if period.From != nil {
+		query = query.Where("issues.lost_at >= ?", period.From.UTC())
+	}
+	if period.To != nil {
+		query = query.Where("issues.lost_at < ?", period.To.UTC())
+	}
+
+	err := query.
+		Group("period, issues.loss_reason").
+		Order("period, count DESC, reason").
+		Scan(&rows).Error
+	return rows, err
+}
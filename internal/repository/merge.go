@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+	"calc_example/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// MergeIssues сохраняет итоговую заявку со ссылками на товары, переносит в нее
+// теги, напоминания, связи, вложения, позиции инвойса и журнал
+// заявок-источников и перемещает источники в корзину со ссылкой на итоговую
+// заявку. Все изменения выполняются в одной транзакции.
+func (r *Repository) MergeIssues(target *model.Issue, sources []model.Issue, entries []model.AuditEntry) error {
+	ids := make([]uint, 0, len(sources))
+	var tags []model.Tag
+	for _, source := range sources {
+		ids = append(ids, source.ID)
+		tags = append(tags, source.Tags...)
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{db: &database.Database{DB: tx}}
+		if err := txRepo.UpdateIssue(target); err != nil {
+			return err
+		}
+
+		if len(tags) > 0 {
+			if err := tx.Model(target).Association("Tags").Append(tags); err != nil {
+				return err
+			}
+		}
+
+		moves := []struct {
+			model  interface{}
+			column string
+		}{
+			{&model.Reminder{}, "issue_id"},
+			{&model.AuditEntry{}, "issue_id"},
+			{&model.Issue{}, "duplicate_of_id"},
+			{&model.Issue{}, "merged_into_id"},
+			{&model.Issue{}, "cloned_from_id"},
+			{&model.IssueLink{}, "issue_id"},
+			{&model.IssueLink{}, "linked_issue_id"},
+			{&model.Attachment{}, "issue_id"},
+			{&model.LineItem{}, "issue_id"},
+		}
+		for _, move := range moves {
+			err := tx.Unscoped().Model(move.model).
+				Where(move.column+" IN ?", ids).
+				UpdateColumn(move.column, target.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// Позиции источников нумеруются после позиций итоговой заявки
+		for _, item := range target.LineItems {
+			if err := tx.Model(&model.LineItem{}).Where("id = ?", item.ID).UpdateColumn("position", item.Position).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := replaceProductLinks(tx, target.ID, target.ProductLinks); err != nil {
+			return err
+		}
+
+		// Связи между объединяемыми заявками теряют смысл
+		if err := tx.Where("issue_id = linked_issue_id").Delete(&model.IssueLink{}).Error; err != nil {
+			return err
+		}
+		for _, column := range []string{"duplicate_of_id", "merged_into_id", "cloned_from_id"} {
+			err := tx.Model(&model.Issue{}).
+				Where("id = ? AND "+column+" = ?", target.ID, target.ID).
+				UpdateColumn(column, nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Issue{}).Where("id IN ?", ids).UpdateColumn("merged_into_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&model.Issue{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&entries).Error
+	})
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+	"calc_example/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// Product Repository
+
+// SaveProductLinks сохраняет заявку и заменяет ее ссылки на товары
+// в одной транзакции. Заявка сохраняется с проверкой версии
+func (r *Repository) SaveProductLinks(issue *model.Issue, links []model.ProductLink) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{db: &database.Database{DB: tx}}
+		if err := txRepo.UpdateIssue(issue); err != nil {
+			return err
+		}
+		return replaceProductLinks(tx, issue.ID, links)
+	})
+}
+
+// SetProductLinks заменяет ссылки на товары заявки, не меняя саму заявку
+func (r *Repository) SetProductLinks(issueID uint, links []model.ProductLink) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceProductLinks(tx, issueID, links)
+	})
+}
+
+func replaceProductLinks(tx *gorm.DB, issueID uint, links []model.ProductLink) error {
+	if err := tx.Where("issue_id = ?", issueID).Delete(&model.ProductLink{}).Error; err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	for i := range links {
+		links[i].ID = 0
+		links[i].IssueID = issueID
+	}
+	return tx.Create(&links).Error
+}
+
+// GetIssuesWithoutProductLinks возвращает заявки со ссылками в тексте,
+// для которых еще не сохранены ссылки на товары
+func (r *Repository) GetIssuesWithoutProductLinks() ([]model.Issue, error) {
+	var issues []model.Issue
+	err := r.db.Unscoped().
+		Where("existing_product_links <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM product_links WHERE product_links.issue_id = issues.id)").
+		Find(&issues).Error
+	return issues, err
+}
+
+// GetProductGroups возвращает товары, на которые ссылаются не меньше
+// minIssues заявок, начиная с самых популярных
+func (r *Repository) GetProductGroups(minIssues int) ([]model.ProductGroup, error) {
+	var groups []model.ProductGroup
+	err := r.db.Table("product_links").
+		Select("product_links.marketplace, product_links.product_id, MIN(product_links.url) AS url, "+
+			"COUNT(DISTINCT product_links.issue_id) AS issue_count, MAX(product_links.issue_id) AS last_issue_id").
+		Joins("JOIN issues ON issues.id = product_links.issue_id AND issues.deleted_at IS NULL").
+		Where("product_links.product_id <> ''").
+		Group("product_links.marketplace, product_links.product_id").
+		Having("COUNT(DISTINCT product_links.issue_id) >= ?", minIssues).
+		Order("issue_count DESC, last_issue_id DESC").
+		Scan(&groups).Error
+	return groups, err
+}
+
+// GetProductIssues возвращает заявки со ссылкой на товар
+func (r *Repository) GetProductIssues(marketplace, productID string) ([]model.Issue, error) {
+	linked := r.db.Model(&model.ProductLink{}).
+		Select("issue_id").
+		Where("marketplace = ? AND product_id = ?", marketplace, productID)
+
+	var issues []model.Issue
+	err := withIssueRelations(r.db.DB).
+		Where("id IN (?)", linked).
+		Order("created_at DESC").
+		Find(&issues).Error
+	return issues, err
+}
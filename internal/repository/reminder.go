@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"calc_example/internal/model"
+)
+
+// Reminder Repository
+func (r *Repository) CreateReminder(reminder *model.Reminder) error {
+	return r.db.Create(reminder).Error
+}
+
+func (r *Repository) GetIssueReminders(issueID uint) ([]model.Reminder, error) {
+	var reminders []model.Reminder
+	err := r.db.Where("issue_id = ?", issueID).Order("remind_at").Find(&reminders).Error
+	return reminders, err
+}
+
+// GetDueReminders возвращает невыполненные напоминания, время которых наступило
+func (r *Repository) GetDueReminders(now time.Time) ([]model.Reminder, error) {
+	var reminders []model.Reminder
+	err := r.db.Where("done_at IS NULL AND remind_at <= ?", now.UTC()).Order("remind_at").Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *Repository) CompleteReminder(id uint, doneAt time.Time) error {
+	return r.db.Model(&model.Reminder{}).Where("id = ?", id).Update("done_at", doneAt).Error
+}
+
+func (r *Repository) DeleteReminder(issueID, id uint) (bool, error) {
+	result := r.db.Where("issue_id = ?", issueID).Delete(&model.Reminder{}, id)
+	return result.RowsAffected > 0, result.Error
+}
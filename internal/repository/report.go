@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Report Repository
+
+// GetSourceReport возвращает количество заявок, успешно закрытых заявок
+// и выручку по источникам и рекламным кампаниям
+func (r *Repository) GetSourceReport(period model.ReportPeriod) ([]model.SourceReportRow, error) {
+	var rows []model.SourceReportRow
+	err := r.reportScope(period).
+		Model(&model.Issue{}).
+		Select(`source,
+			utm_campaign AS campaign,
+			COUNT(*) AS leads,
+			COALESCE(SUM(CASE WHEN status = @won THEN 1 ELSE 0 END), 0) AS conversions,
+			COALESCE(SUM(CASE WHEN status = @won THEN quote_amount END), 0) AS revenue`,
+			map[string]interface{}{"won": model.StatusClosed}).
+		Group("source, utm_campaign").
+		Order("leads DESC, source, utm_campaign").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// reportScope ограничивает выборку заявок периодом отчета
+func (r *Repository) reportScope(period model.ReportPeriod) *gorm.DB {
+	query := r.db.DB
+	if period.From != nil {
+		query = query.Where("issues.created_at >= ?", period.From.UTC())
+	}
+	if period.To != nil {
+		query = query.Where("issues.created_at < ?", period.To.UTC())
+	}
+	return query
+}
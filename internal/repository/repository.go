@@ -1,10 +1,22 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"calc_example/internal/model"
 	"calc_example/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict возвращается, если заявка была изменена параллельно
+var ErrVersionConflict = errors.New("заявка была изменена другим пользователем")
+
 type Repository struct {
 	db *database.Database
 }
@@ -13,6 +25,26 @@ func New(db *database.Database) *Repository {
 	return &Repository{db: db}
 }
 
+// withIssueRelations подгружает связанные с заявкой теги, связи, вложения,
+// позиции инвойса и ссылки на товары
+func withIssueRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").Preload("Links").Preload("BackLinks").Preload("Attachments").
+		Preload("LineItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("ProductLinks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		})
+}
+
+// Transaction выполняет fn в одной транзакции. Репозиторий, переданный
+// в fn, работает внутри этой транзакции
+func (r *Repository) Transaction(fn func(tx *Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Repository{db: &database.Database{DB: tx}})
+	})
+}
+
 // Issue Repository
 func (r *Repository) CreateIssue(issue *model.Issue) error {
 	return r.db.Create(issue).Error
@@ -20,23 +52,246 @@ func (r *Repository) CreateIssue(issue *model.Issue) error {
 
 func (r *Repository) GetIssueByID(id uint) (*model.Issue, error) {
 	var issue model.Issue
-	err := r.db.First(&issue, id).Error
+	err := withIssueRelations(r.db.DB).First(&issue, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &issue, nil
 }
 
-func (r *Repository) GetAllIssues() ([]model.Issue, error) {
+// issueSortColumns - столбцы для полей сортировки списка заявок
+var issueSortColumns = map[string]string{
+	model.IssueSortCreatedAt: "created_at",
+	model.IssueSortUpdatedAt: "updated_at",
+	model.IssueSortScore:     "score",
+	model.IssueSortFullName:  "full_name",
+	model.IssueSortStatus:    "status",
+}
+
+// GetAllIssues возвращает страницу заявок, подходящих под фильтр,
+// и общее количество таких заявок. Если задан cursor, страница
+// отсчитывается от него, а не от номера страницы фильтра
+func (r *Repository) GetAllIssues(filter model.IssueFilter, cursor *model.IssueCursor) ([]model.Issue, int64, error) {
+	query := r.filterIssues(filter).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Model(&model.Issue{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if cursor == nil {
+		var issues []model.Issue
+		err := withIssueRelations(orderIssues(query, filter)).
+			Limit(filter.Limit).
+			Offset((filter.Page - 1) * filter.Limit).
+			Find(&issues).Error
+		return issues, total, err
+	}
+
+	// Страница перед курсором выбирается в обратном порядке
+	// и разворачивается после выборки
+	if cursor.Before {
+		filter.SortAsc = !filter.SortAsc
+	}
+	compare := "<"
+	if filter.SortAsc {
+		compare = ">"
+	}
+
+	createdAt := cursor.CreatedAt.UTC()
 	var issues []model.Issue
-	err := r.db.Order("created_at DESC").Find(&issues).Error
-	return issues, err
+	err := withIssueRelations(orderIssues(query, filter)).
+		Where("(created_at "+compare+" ? OR (created_at = ? AND id "+compare+" ?))",
+			createdAt, createdAt, cursor.ID).
+		Limit(filter.Limit).
+		Find(&issues).Error
+	if cursor.Before {
+		slices.Reverse(issues)
+	}
+	return issues, total, err
+}
+
+// EachIssue вызывает fn для каждой заявки, подходящей под фильтр, в порядке
+// сортировки фильтра, не загружая все заявки сразу. Заявки читаются пачками
+// по batch вместе с тегами, каждая пачка начинается после ключа сортировки
+// последней заявки предыдущей, поэтому изменения заявок во время обхода
+// не сдвигают пачки. Заявки, созданные после начала обхода, пропускаются
+func (r *Repository) EachIssue(filter model.IssueFilter, batch int, fn func(*model.Issue) error) error {
+	var lastID uint
+	if err := r.db.Model(&model.Issue{}).Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error; err != nil {
+		return err
+	}
+
+	key := issueSortKey(filter)
+	compare := "<"
+	if filter.SortAsc {
+		compare = ">"
+	}
+	after := fmt.Sprintf("(%s) %s (%s)", strings.Join(key, ", "), compare,
+		strings.TrimSuffix(strings.Repeat("?, ", len(key)), ", "))
+
+	query := orderIssues(r.filterIssues(filter), filter).
+		Where("id <= ?", lastID).
+		Preload("Tags").
+		Session(&gorm.Session{})
+
+	var last []interface{}
+	for {
+		page := query
+		if last != nil {
+			page = page.Where(after, last...)
+		}
+
+		var issues []model.Issue
+		if err := page.Limit(batch).Find(&issues).Error; err != nil {
+			return err
+		}
+		for i := range issues {
+			if err := fn(&issues[i]); err != nil {
+				return err
+			}
+		}
+		if len(issues) < batch {
+			return nil
+		}
+		last = issueSortValues(&issues[len(issues)-1], key)
+	}
+}
+
+// filterIssues возвращает запрос заявок с условиями фильтра
+func (r *Repository) filterIssues(filter model.IssueFilter) *gorm.DB {
+	query := r.db.Model(&model.Issue{})
+
+	if len(filter.Tags) > 0 {
+		tagged := r.db.Table("issue_tags").
+			Select("issue_tags.issue_id").
+			Joins("JOIN tags ON tags.id = issue_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("issue_tags.issue_id").
+				Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	switch filter.Assignee {
+	case "":
+	case model.IssueAssigneeNone:
+		query = query.Where("COALESCE(assignee, '') = ''")
+	default:
+		query = query.Where("assignee = ?", filter.Assignee)
+	}
+	if filter.HasChinaExperience != nil {
+		query = query.Where("has_china_experience = ?", *filter.HasChinaExperience)
+	}
+	if filter.Created.From != nil {
+		query = query.Where("created_at >= ?", filter.Created.From.UTC())
+	}
+	if filter.Created.To != nil {
+		query = query.Where("created_at < ?", filter.Created.To.UTC())
+	}
+	return query
+}
+
+// orderIssues упорядочивает заявки по полю сортировки фильтра. При равных
+// значениях заявки упорядочиваются по дате создания и ID, чтобы порядок
+// страниц был устойчивым
+func orderIssues(query *gorm.DB, filter model.IssueFilter) *gorm.DB {
+	direction := " DESC"
+	if filter.SortAsc {
+		direction = " ASC"
+	}
+
+	for _, column := range issueSortKey(filter) {
+		query = query.Order(column + direction)
+	}
+	return query
+}
+
+// issueSortKey возвращает столбцы, по которым упорядочиваются заявки:
+// поле сортировки фильтра, время создания и номер заявки
+func issueSortKey(filter model.IssueFilter) []string {
+	column, ok := issueSortColumns[filter.SortBy]
+	if !ok || column == "created_at" {
+		return []string{"created_at", "id"}
+	}
+	return []string{column, "created_at", "id"}
+}
+
+// issueSortValues возвращает значения столбцов key заявки issue
+func issueSortValues(issue *model.Issue, key []string) []interface{} {
+	values := make([]interface{}, len(key))
+	for i, column := range key {
+		switch column {
+		case "created_at":
+			values[i] = issue.CreatedAt
+		case "updated_at":
+			values[i] = issue.UpdatedAt
+		case "score":
+			values[i] = issue.Score
+		case "full_name":
+			values[i] = issue.FullName
+		case "status":
+			values[i] = issue.Status
+		case "id":
+			values[i] = issue.ID
+		}
+	}
+	return values
 }
 
+// UpdateIssue сохраняет заявку, если с момента ее загрузки она не была
+// изменена другим запросом, и увеличивает версию заявки
 func (r *Repository) UpdateIssue(issue *model.Issue) error {
-	return r.db.Save(issue).Error
+	version := issue.Version
+	issue.Version++
+
+	result := r.db.Model(issue).
+		Where("version = ?", version).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(issue)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		issue.Version = version
+	}
+	return result.Error
+}
+
+// DeleteIssue перемещает заявку в корзину, если с момента ее загрузки
+// она не была изменена другим запросом
+func (r *Repository) DeleteIssue(issue *model.Issue) error {
+	result := r.db.Where("version = ?", issue.Version).Delete(issue)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return result.Error
 }
 
-func (r *Repository) DeleteIssue(id uint) error {
-	return r.db.Delete(&model.Issue{}, id).Error
+// GetDuplicateCandidates возвращает заявки, созданные начиная с since,
+// которые могут быть повтором: все заявки с контактом contactKey,
+// а за ними limit последних заявок. Загружаются только поля для сравнения
+func (r *Repository) GetDuplicateCandidates(since time.Time, contactKey string, limit int) ([]model.Issue, error) {
+	recent := func() *gorm.DB {
+		return r.db.Select("id, full_name, product_description, contact_key, duplicate_of_id, created_at").
+			Where("created_at >= ?", since.UTC()).
+			Order("created_at DESC, id DESC")
+	}
+
+	var issues []model.Issue
+	if contactKey != "" {
+		if err := recent().Where("contact_key = ?", contactKey).Find(&issues).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var latest []model.Issue
+	if err := recent().Limit(limit).Find(&latest).Error; err != nil {
+		return nil, err
+	}
+	return append(issues, latest...), nil
 }
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"calc_example/internal/model"
+	"calc_example/internal/search"
+	"calc_example/pkg/database"
+)
+
+// searchWeights - веса столбцов индекса при ранжировании: совпадение
+// в имени или контактах важнее совпадения в описании и заметках
+var searchWeights = []float64{10, 10, 4, 2, 1}
+
+// searchSnippetTokens - длина фрагмента поля в словах
+const searchSnippetTokens = 12
+
+// SearchIssues ищет заявки по запросу FTS match и возвращает не больше
+// limit совпадений по убыванию релевантности. Удаленные заявки не ищутся
+func (r *Repository) SearchIssues(match string, limit int) ([]model.IssueSearchHit, error) {
+	engine, err := database.SearchEngine(r.db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []model.IssueSearchHit
+	if engine == database.SearchFTS5 {
+		hits, err = r.searchFTS5(match, limit)
+	} else {
+		hits, err = r.searchFTS4(match, limit)
+	}
+	if err != nil || len(hits) == 0 {
+		return hits, err
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.IssueID
+	}
+	snippets, err := r.searchSnippets(engine, match, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Snippets = snippets[hits[i].IssueID]
+	}
+	return hits, nil
+}
+
+// searchFTS5 ранжирует совпадения встроенной функцией bm25
+func (r *Repository) searchFTS5(match string, limit int) ([]model.IssueSearchHit, error) {
+	weights := make([]string, len(searchWeights))
+	for i, weight := range searchWeights {
+		weights[i] = fmt.Sprint(weight)
+	}
+
+	var hits []model.IssueSearchHit
+	err := r.db.Raw(fmt.Sprintf(
+		`SELECT %[1]s.rowid AS issue_id, -bm25(%[1]s, %[2]s) AS rank FROM %[1]s
+		JOIN issues ON issues.id = %[1]s.rowid AND issues.deleted_at IS NULL
+		WHERE %[1]s MATCH ? ORDER BY rank DESC, issue_id DESC LIMIT ?`,
+		database.SearchTable, strings.Join(weights, ", ")), match, limit).
+		Scan(&hits).Error
+	return hits, err
+}
+
+// searchFTS4 ранжирует совпадения по matchinfo: в FTS4 нет встроенного
+// ранжирования, поэтому оценки считаются для всех совпадений
+func (r *Repository) searchFTS4(match string, limit int) ([]model.IssueSearchHit, error) {
+	rows, err := r.db.Raw(fmt.Sprintf(
+		`SELECT %[1]s.docid, matchinfo(%[1]s, 'pcx') FROM %[1]s
+		JOIN issues ON issues.id = %[1]s.docid AND issues.deleted_at IS NULL
+		WHERE %[1]s MATCH ?`, database.SearchTable), match).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []model.IssueSearchHit
+	for rows.Next() {
+		var hit model.IssueSearchHit
+		var info []byte
+		if err := rows.Scan(&hit.IssueID, &info); err != nil {
+			return nil, err
+		}
+		hit.Rank = search.Rank(info, searchWeights)
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].IssueID > hits[j].IssueID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// searchSnippets возвращает фрагменты всех столбцов индекса для заявок ids
+func (r *Repository) searchSnippets(engine, match string, ids []uint) (map[uint][]string, error) {
+	columns := make([]string, len(model.IssueSearchFields))
+	for i := range columns {
+		if engine == database.SearchFTS5 {
+			columns[i] = fmt.Sprintf("snippet(%s, %d, ?, ?, ?, %d)", database.SearchTable, i, searchSnippetTokens)
+		} else {
+			columns[i] = fmt.Sprintf("snippet(%s, ?, ?, ?, %d, %d)", database.SearchTable, i, searchSnippetTokens)
+		}
+	}
+
+	args := make([]interface{}, 0, len(columns)*3+2)
+	for range columns {
+		args = append(args, search.HighlightStart, search.HighlightEnd, search.Ellipsis)
+	}
+	args = append(args, match, ids)
+
+	rows, err := r.db.Raw(fmt.Sprintf("SELECT rowid, %[1]s FROM %[2]s WHERE %[2]s MATCH ? AND rowid IN ?",
+		strings.Join(columns, ", "), database.SearchTable), args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snippets := make(map[uint][]string, len(ids))
+	for rows.Next() {
+		var id uint
+		values := make([]string, len(columns))
+		dest := []interface{}{&id}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		snippets[id] = values
+	}
+	return snippets, rows.Err()
+}
+
+// GetIssuesByIDs возвращает заявки ids со связанными данными в любом порядке
+func (r *Repository) GetIssuesByIDs(ids []uint) ([]model.Issue, error) {
+	var issues []model.Issue
+	err := withIssueRelations(r.db.DB).Where("id IN ?", ids).Find(&issues).Error
+	return issues, err
+}
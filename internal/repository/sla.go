@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+)
+
+// SLA Repository
+
+// GetSLAPendingIssues возвращает активные заявки с невыполненными
+// показателями SLA, по которым эскалация еще не отправлялась
+func (r *Repository) GetSLAPendingIssues() ([]model.Issue, error) {
+	var issues []model.Issue
+	err := r.db.Preload("Tags").
+		Where("status NOT IN ?", []string{model.StatusClosed, model.StatusLost}).
+		Where("(first_contact_at IS NULL AND first_contact_escalated = ?) OR (quoted_at IS NULL AND quote_escalated = ?)", false, false).
+		Find(&issues).Error
+	return issues, err
+}
+
+func (r *Repository) MarkSLAEscalated(id uint, metric string) error {
+	column := "first_contact_escalated"
+	if metric == model.SLAMetricQuote {
+		column = "quote_escalated"
+	}
+	return r.db.Model(&model.Issue{}).Where("id = ?", id).UpdateColumn(column, true).Error
+}
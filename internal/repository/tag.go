@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+	"calc_example/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// Tag Repository
+func (r *Repository) GetAllTags() ([]model.Tag, error) {
+	var tags []model.Tag
+	err := r.db.Order("predefined DESC, name").Find(&tags).Error
+	return tags, err
+}
+
+// FindOrCreateTags возвращает теги с указанными именами, создавая недостающие
+func (r *Repository) FindOrCreateTags(names []string) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		tag := model.Tag{Name: name}
+		if err := r.db.Where(model.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// AddIssueTags добавляет теги заявке и увеличивает ее версию
+func (r *Repository) AddIssueTags(issue *model.Issue, tags []model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{db: &database.Database{DB: tx}}
+		if err := txRepo.UpdateIssue(issue); err != nil {
+			return err
+		}
+		return tx.Model(issue).Association("Tags").Append(tags)
+	})
+}
+
+// RemoveIssueTag снимает тег с заявки и увеличивает ее версию.
+// Если тега у заявки нет, возвращает gorm.ErrRecordNotFound
+func (r *Repository) RemoveIssueTag(issue *model.Issue, tag *model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		txRepo := &Repository{db: &database.Database{DB: tx}}
+		if err := txRepo.UpdateIssue(issue); err != nil {
+			return err
+		}
+		result := tx.Exec("DELETE FROM issue_tags WHERE issue_id = ? AND tag_id = ?", issue.ID, tag.ID)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+}
+
+func (r *Repository) GetTagByName(name string) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Where("name = ?", name).First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Trash Repository
+func (r *Repository) GetDeletedIssues() ([]model.Issue, error) {
+	var issues []model.Issue
+	err := withIssueRelations(r.db.Unscoped()).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&issues).Error
+	return issues, err
+}
+
+func (r *Repository) GetDeletedIssueByID(id uint) (*model.Issue, error) {
+	var issue model.Issue
+	err := withIssueRelations(r.db.Unscoped()).Where("deleted_at IS NOT NULL").First(&issue, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+func (r *Repository) RestoreIssue(id uint) error {
+	return r.db.Unscoped().Model(&model.Issue{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+// PurgeIssue окончательно удаляет заявку вместе с зависимыми записями
+// и ссылками на нее из других заявок
+func (r *Repository) PurgeIssue(issue *model.Issue) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, column := range []string{"duplicate_of_id", "merged_into_id", "cloned_from_id"} {
+			err := tx.Unscoped().Model(&model.Issue{}).
+				Where(column+" = ?", issue.ID).
+				UpdateColumn(column, nil).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("issue_id = ?", issue.ID).Delete(&model.Reminder{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(issue).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("issue_id = ? OR linked_issue_id = ?", issue.ID, issue.ID).Delete(&model.IssueLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("issue_id = ?", issue.ID).Delete(&model.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("issue_id = ?", issue.ID).Delete(&model.LineItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("issue_id = ?", issue.ID).Delete(&model.ProductLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Issue{}, issue.ID).Error
+	})
+}
@@ -0,0 +1,106 @@
+// Package search готовит поисковые запросы к полнотекстовому индексу
+// заявок: разбивает запрос на слова, приводит русские слова к основе
+// и ранжирует совпадения, если индекс не умеет делать это сам.
+package search
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode"
+)
+
+// Разметка совпадений во фрагментах результатов поиска
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+	Ellipsis       = "…"
+)
+
+// MaxTerms - сколько слов запроса учитывается при поиске
+const MaxTerms = 10
+
+// Terms разбивает запрос на слова в нижнем регистре. Русские слова
+// приводятся к основе, чтобы запрос находил и другие их формы.
+// Слова короче двух символов пропускаются
+func Terms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+
+	words := strings.FieldsFunc(Normalize(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		term := word
+		if isRussian(word) {
+			if stem := Stem(word); len([]rune(stem)) >= 2 {
+				term = stem
+			}
+		}
+		if len([]rune(term)) < 2 || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+		if len(terms) == MaxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// Normalize приводит текст к виду, в котором он хранится в индексе:
+// нижний регистр и «е» вместо «ё»
+func Normalize(text string) string {
+	return strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+}
+
+// MatchQuery собирает из слов Terms запрос FTS: заявка должна содержать
+// все слова, каждое слово ищется как начало слова в тексте. Слова состоят
+// только из букв и цифр в нижнем регистре, поэтому не экранируются
+// и не совпадают с операторами AND, OR и NOT
+func MatchQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Rank оценивает совпадение по результату matchinfo(..., 'pcx') индекса
+// FTS4, в котором нет встроенного ранжирования. Каждое вхождение слова
+// в столбец дает вес столбца, деленный на число вхождений слова во все
+// заявки, поэтому редкие слова значат больше частых
+func Rank(matchinfo []byte, weights []float64) float64 {
+	values := make([]uint32, len(matchinfo)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(matchinfo[i*4:])
+	}
+	if len(values) < 2 {
+		return 0
+	}
+
+	phrases, columns := int(values[0]), int(values[1])
+	if len(values) < 2+phrases*columns*3 {
+		return 0
+	}
+
+	var rank float64
+	for phrase := 0; phrase < phrases; phrase++ {
+		for column := 0; column < columns && column < len(weights); column++ {
+			hits := values[2+(phrase*columns+column)*3:]
+			if hits[0] > 0 && hits[1] > 0 {
+				rank += weights[column] * float64(hits[0]) / float64(hits[1])
+			}
+		}
+	}
+	return rank
+}
+
+func isRussian(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
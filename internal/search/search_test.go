@@ -0,0 +1,30 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Настольные лампы", []string{"настольн", "ламп"}},
+		{"Ёлочные игрушки, ёлочные!", []string{"елочн", "игрушк"}},
+		{"anna@example.com", []string{"anna", "example", "com"}},
+		{"+7 999 123", []string{"999", "123"}},
+		{"я и ты", []string{"ты"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, ожидалось %q", tt.query, got, tt.want)
+		}
+	}
+
+	if got := MatchQuery([]string{"настольн", "ламп"}); got != "настольн* ламп*" {
+		t.Errorf("MatchQuery = %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package search
+
+import "strings"
+
+// Окончания русского стеммера Snowball. Окончания из первых групп
+// отбрасываются, только если перед ними стоит «а» или «я»
+var (
+	gerundAfterA  = []string{"в", "вши", "вшись"}
+	gerund        = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+	adjective     = []string{"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+	participleA   = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle    = []string{"ивш", "ывш", "ующ"}
+	reflexive     = []string{"ся", "сь"}
+	verbAfterA    = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	verb          = []string{"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю"}
+	noun          = []string{"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я"}
+	derivational  = []string{"ост", "ость"}
+	superlative   = []string{"ейш", "ейше"}
+	russianVowels = "аеиоуыэюя"
+)
+
+// Stem возвращает основу русского слова по алгоритму Snowball.
+// Слово должно быть в нижнем регистре, «ё» заменяется на «е»
+func Stem(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+
+	rv := len(w)
+	for i, r := range w {
+		if isVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	r2 := region(w, region(w, 0))
+
+	// Шаг 1: деепричастие, иначе возвратная частица и окончание
+	// прилагательного, глагола или существительного
+	if n := strip(w, rv, gerundAfterA, gerund); n > 0 {
+		w = w[:len(w)-n]
+	} else {
+		if n := strip(w, rv, nil, reflexive); n > 0 {
+			w = w[:len(w)-n]
+		}
+		if n := strip(w, rv, nil, adjective); n > 0 {
+			w = w[:len(w)-n]
+			if n := strip(w, rv, participleA, participle); n > 0 {
+				w = w[:len(w)-n]
+			}
+		} else if n := strip(w, rv, verbAfterA, verb); n > 0 {
+			w = w[:len(w)-n]
+		} else if n := strip(w, rv, nil, noun); n > 0 {
+			w = w[:len(w)-n]
+		}
+	}
+
+	// Шаг 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Шаг 3: словообразовательный суффикс в R2
+	if n := strip(w, max(rv, r2), nil, derivational); n > 0 {
+		w = w[:len(w)-n]
+	}
+
+	// Шаг 4: превосходная степень, двойная «н» и мягкий знак
+	doubleN := func() bool {
+		return len(w)-2 >= rv && w[len(w)-1] == 'н' && w[len(w)-2] == 'н'
+	}
+	if n := strip(w, rv, nil, superlative); n > 0 {
+		w = w[:len(w)-n]
+		if doubleN() {
+			w = w[:len(w)-1]
+		}
+	} else if doubleN() {
+		w = w[:len(w)-1]
+	} else if len(w) > rv && w[len(w)-1] == 'ь' {
+		w = w[:len(w)-1]
+	}
+
+	return string(w)
+}
+
+// strip возвращает длину самого длинного окончания из afterA или other,
+// которое целиком лежит в w[start:], либо 0. Окончание из afterA подходит,
+// только если перед ним в той же области стоит «а» или «я»
+func strip(w []rune, start int, afterA, other []string) int {
+	best, needA := 0, false
+	for _, group := range []struct {
+		endings []string
+		afterA  bool
+	}{{afterA, true}, {other, false}} {
+		for _, ending := range group.endings {
+			n := len([]rune(ending))
+			if n > best && len(w)-n >= start && string(w[len(w)-n:]) == ending {
+				best, needA = n, group.afterA
+			}
+		}
+	}
+	if best == 0 {
+		return 0
+	}
+	if needA {
+		i := len(w) - best - 1
+		if i < start || (w[i] != 'а' && w[i] != 'я') {
+			return 0
+		}
+	}
+	return best
+}
+
+// region возвращает начало области после первой согласной,
+// следующей за гласной, начиная с позиции from
+func region(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune(russianVowels, r)
+}
@@ -0,0 +1,32 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	// Ожидаемые основы совпадают с эталонной реализацией Snowball
+	tests := map[string]string{
+		"книги":       "книг",
+		"красивая":    "красив",
+		"заказы":      "заказ",
+		"заказов":     "заказ",
+		"китая":       "кит",
+		"китай":       "кита",
+		"электроники": "электроник",
+		"поставщиков": "поставщик",
+		"доставка":    "доставк",
+		"вежливость":  "вежлив",
+		"важнейшие":   "важн",
+		"длинная":     "длин",
+		"вагоны":      "вагон",
+		"вавиловка":   "вавиловк",
+		"ёлочные":     "елочн",
+		"прочитавши":  "прочита",
+		"одевалась":   "одева",
+	}
+
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, ожидалось %q", word, got, want)
+		}
+	}
+}
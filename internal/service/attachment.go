@@ -0,0 +1,271 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"calc_example/internal/model"
+	"calc_example/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentNotFound    = errors.New("вложение не найдено")
+	ErrNoFiles               = errors.New("не передано ни одного файла")
+	ErrAttachmentQuarantined = errors.New("вложение находится в карантине")
+	ErrThumbnailNotFound     = errors.New("у вложения нет превью")
+)
+
+// quarantinePrefix - каталог хранилища для файлов в карантине
+const quarantinePrefix = "quarantine/"
+
+// Адреса скачивания вложения и его превью
+const (
+	attachmentURL = "/api/v1/attachments/%d"
+	thumbnailURL  = "/api/v1/attachments/%d/thumbnail"
+)
+
+// thumbnailSuffix дописывается к ключу вложения для его превью
+const thumbnailSuffix = ".thumb.jpg"
+
+// AttachmentContent - файл вложения или его превью для скачивания: либо
+// временная ссылка на файл в хранилище, либо его содержимое
+type AttachmentContent struct {
+	Attachment *model.Attachment
+	FileName   string
+	MimeType   string
+	// Size равен -1, если размер файла неизвестен
+	Size    int64
+	ETag    string
+	URL     string
+	Content io.ReadCloser
+}
+
+// Attachment Service
+
+// AddAttachments прикладывает загруженные файлы к заявке. Данные инвойса
+// заявки заполняются только из файла с именем previousInvoiceFile, другие
+// файлы извлеченные данные не перезаписывают
+func (s *Service) AddAttachments(id uint, files []model.FileUpload, previousInvoiceFile string) ([]model.AttachmentView, error) {
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+	issue, err := s.getIssue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.repo.GetIssueAttachmentsSize(id)
+	if err != nil {
+		return nil, err
+	}
+	uploads, err := s.inspectUploads(files, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.storeAttachments(uploads)
+	if err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		attachments[i].IssueID = id
+	}
+	if err := s.repo.CreateAttachments(attachments); err != nil {
+		s.removeFiles(attachments)
+		return nil, err
+	}
+
+	// Файлы уже сохранены, поэтому параллельное изменение заявки не делает
+	// загрузку ошибочной: данные инвойса просто не заполняются
+	if err := s.fillInvoice(issue, uploads, previousInvoiceFile); err != nil && !errors.Is(err, ErrVersionConflict) {
+		return nil, err
+	}
+
+	return attachmentViews(attachments), nil
+}
+
+// GetAttachment возвращает данные вложения
+func (s *Service) GetAttachment(id uint) (*model.Attachment, error) {
+	attachment, err := s.repo.GetAttachmentByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return attachment, err
+}
+
+// OpenAttachment готовит вложение к скачиванию. Если хранилище выдает
+// временные ссылки, возвращается ссылка, иначе - содержимое файла
+func (s *Service) OpenAttachment(id uint) (*AttachmentContent, error) {
+	attachment, err := s.openableAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AttachmentContent{
+		Attachment: attachment,
+		FileName:   attachment.FileName,
+		MimeType:   attachment.MimeType,
+		Size:       attachment.Size,
+		ETag:       attachment.Checksum,
+	}
+	return result, s.openFile(result, attachment.StorageKey)
+}
+
+// OpenThumbnail готовит к скачиванию превью изображения
+func (s *Service) OpenThumbnail(id uint) (*AttachmentContent, error) {
+	attachment, err := s.openableAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.ThumbnailKey == "" {
+		return nil, ErrThumbnailNotFound
+	}
+
+	result := &AttachmentContent{
+		Attachment: attachment,
+		FileName:   strings.TrimSuffix(attachment.FileName, filepath.Ext(attachment.FileName)) + "_thumb.jpg",
+		MimeType:   "image/jpeg",
+		Size:       -1,
+		ETag:       attachment.Checksum + "-thumb",
+	}
+	return result, s.openFile(result, attachment.ThumbnailKey)
+}
+
+// openableAttachment возвращает вложение, которое можно отдать клиенту
+func (s *Service) openableAttachment(id uint) (*model.Attachment, error) {
+	attachment, err := s.GetAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.Quarantined {
+		return nil, ErrAttachmentQuarantined
+	}
+	return attachment, nil
+}
+
+// openFile заполняет временную ссылку на файл или его содержимое
+func (s *Service) openFile(content *AttachmentContent, key string) error {
+	var err error
+	if presigner, ok := s.files.(storage.Presigner); ok {
+		content.URL, err = presigner.PresignedURL(key, content.FileName, s.presign)
+		return err
+	}
+
+	content.Content, err = s.files.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrAttachmentNotFound
+	}
+	return err
+}
+
+// ReleaseAttachment снимает вложение с карантина после проверки менеджером
+func (s *Service) ReleaseAttachment(id uint) (*model.AttachmentView, error) {
+	attachment, err := s.GetAttachment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.Quarantined {
+		if err := s.repo.ReleaseAttachment(id); err != nil {
+			return nil, err
+		}
+		attachment.Quarantined = false
+		attachment.QuarantineReason = ""
+	}
+
+	return &attachmentViews([]model.Attachment{*attachment})[0], nil
+}
+
+// storeAttachments сохраняет проверенные файлы в хранилище и считает
+// их контрольные суммы. Файлы для карантина сохраняются отдельно
+func (s *Service) storeAttachments(uploads []inspectedUpload) ([]model.Attachment, error) {
+	attachments := make([]model.Attachment, 0, len(uploads))
+	for _, upload := range uploads {
+		key := storage.NewKey()
+		if upload.QuarantineReason != "" {
+			key = quarantinePrefix + key
+		}
+		if err := s.files.Put(key, upload.Data, upload.MimeType); err != nil {
+			s.removeFiles(attachments)
+			return nil, err
+		}
+
+		thumbnailKey := ""
+		if upload.Thumbnail != nil {
+			thumbnailKey = key + thumbnailSuffix
+			if err := s.files.Put(thumbnailKey, upload.Thumbnail, "image/jpeg"); err != nil {
+				_ = s.files.Delete(key)
+				s.removeFiles(attachments)
+				return nil, err
+			}
+		}
+
+		checksum := sha256.Sum256(upload.Data)
+		attachments = append(attachments, model.Attachment{
+			FileName:         upload.FileName,
+			Size:             int64(len(upload.Data)),
+			MimeType:         upload.MimeType,
+			Checksum:         hex.EncodeToString(checksum[:]),
+			StorageKey:       key,
+			ThumbnailKey:     thumbnailKey,
+			Width:            upload.Width,
+			Height:           upload.Height,
+			Quarantined:      upload.QuarantineReason != "",
+			QuarantineReason: upload.QuarantineReason,
+		})
+	}
+	return attachments, nil
+}
+
+// removeFiles удаляет файлы вложений из хранилища. Ошибки не возвращаются:
+// запись о вложении к этому моменту уже удалена или не была создана,
+// а оставшийся в хранилище файл ни на что не влияет
+func (s *Service) removeFiles(attachments []model.Attachment) {
+	for _, attachment := range attachments {
+		_ = s.files.Delete(attachment.StorageKey)
+		if attachment.ThumbnailKey != "" {
+			_ = s.files.Delete(attachment.ThumbnailKey)
+		}
+	}
+}
+
+// sanitizeFileName оставляет от имени файла только базовое имя без пути
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	return name
+}
+
+func attachmentViews(attachments []model.Attachment) []model.AttachmentView {
+	views := make([]model.AttachmentView, 0, len(attachments))
+	for _, attachment := range attachments {
+		thumbnail := ""
+		if attachment.ThumbnailKey != "" {
+			thumbnail = fmt.Sprintf(thumbnailURL, attachment.ID)
+		}
+		views = append(views, model.AttachmentView{
+			ID:               attachment.ID,
+			FileName:         attachment.FileName,
+			Size:             attachment.Size,
+			MimeType:         attachment.MimeType,
+			Checksum:         attachment.Checksum,
+			URL:              fmt.Sprintf(attachmentURL, attachment.ID),
+			ThumbnailURL:     thumbnail,
+			Width:            attachment.Width,
+			Height:           attachment.Height,
+			CreatedAt:        attachment.CreatedAt,
+			Quarantined:      attachment.Quarantined,
+			QuarantineReason: attachment.QuarantineReason,
+		})
+	}
+	return views
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+func TestIssueAttachments(t *testing.T) {
+	service := newTestService(t)
+
+	invoice := model.FileUpload{FileName: `C:\docs\invoice.pdf`, Data: []byte("%PDF-1.4 test")}
+	created, err := service.CreateIssueWithFiles(newTestIssueRequest(), []model.FileUpload{invoice})
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if len(created.Attachments) != 1 {
+		t.Fatalf("Ожидалось одно вложение, получено %+v", created.Attachments)
+	}
+	view := created.Attachments[0]
+	if view.FileName != "invoice.pdf" || view.MimeType != "application/pdf" || view.Size != int64(len(invoice.Data)) {
+		t.Errorf("Неверные данные вложения: %+v", view)
+	}
+	if len(view.Checksum) != 64 {
+		t.Errorf("Ожидалась контрольная сумма SHA-256, получено %s", view.Checksum)
+	}
+
+	added, err := service.AddAttachments(created.ID, []model.FileUpload{{FileName: "photo.png", Data: testPNG}}, "")
+	if err != nil {
+		t.Fatalf("Ошибка добавления вложения: %v", err)
+	}
+
+	download, err := service.OpenAttachment(added[0].ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения вложения: %v", err)
+	}
+	content, err := io.ReadAll(download.Content)
+	download.Content.Close()
+	if err != nil {
+		t.Fatalf("Ошибка чтения вложения: %v", err)
+	}
+	if !bytes.Equal(content, testPNG) || download.Attachment.IssueID != created.ID {
+		t.Errorf("Неверное содержимое вложения: %s %+v", content, download.Attachment)
+	}
+
+	issue, err := service.GetIssueByID(created.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if len(issue.Attachments) != 2 {
+		t.Errorf("Ожидалось два вложения в заявке, получено %+v", issue.Attachments)
+	}
+
+	if _, err := service.AddAttachments(created.ID, nil, ""); !errors.Is(err, ErrNoFiles) {
+		t.Errorf("Ожидалась ошибка отсутствия файлов, получено %v", err)
+	}
+	if _, err := service.OpenAttachment(999); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия вложения, получено %v", err)
+	}
+}
+
+func TestImageAttachmentThumbnail(t *testing.T) {
+	service := newTestService(t)
+	service.upload.AllowedTypes = append(service.upload.AllowedTypes, "image/jpeg")
+
+	// Сегмент EXIF с данными камеры вставляется сразу после начала файла
+	photo := newTestImage("image/jpeg")
+	exif := append([]byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00"), "SecretCamera"...)
+	segment := append([]byte{0xff, 0xe1, 0, byte(len(exif) + 2)}, exif...)
+	photo = append(append(append([]byte{}, photo[:2]...), segment...), photo[2:]...)
+
+	created, err := service.CreateIssueWithFiles(newTestIssueRequest(), []model.FileUpload{
+		{FileName: "photo.jpg", Data: photo},
+		{FileName: "invoice.pdf", Data: []byte("%PDF-1.4 test")},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	image, pdf := created.Attachments[0], created.Attachments[1]
+	if image.ThumbnailURL == "" || image.Width != 2 || image.Height != 2 {
+		t.Errorf("Ожидалось превью изображения с размерами, получено %+v", image)
+	}
+	if pdf.ThumbnailURL != "" {
+		t.Errorf("У PDF не должно быть превью, получено %s", pdf.ThumbnailURL)
+	}
+
+	download, err := service.OpenAttachment(image.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения вложения: %v", err)
+	}
+	content, _ := io.ReadAll(download.Content)
+	download.Content.Close()
+	if bytes.Contains(content, []byte("SecretCamera")) || int64(len(content)) != image.Size {
+		t.Errorf("Метаданные изображения не удалены")
+	}
+
+	thumbnail, err := service.OpenThumbnail(image.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения превью: %v", err)
+	}
+	content, _ = io.ReadAll(thumbnail.Content)
+	thumbnail.Content.Close()
+	if thumbnail.MimeType != "image/jpeg" || thumbnail.FileName != "photo_thumb.jpg" || !bytes.HasPrefix(content, []byte{0xff, 0xd8}) {
+		t.Errorf("Неверное превью: %s %s", thumbnail.FileName, thumbnail.MimeType)
+	}
+
+	if _, err := service.OpenThumbnail(pdf.ID); !errors.Is(err, ErrThumbnailNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия превью, получено %v", err)
+	}
+
+	if _, err := service.AddAttachments(created.ID, []model.FileUpload{{FileName: "broken.png", Data: testPNG[:40]}}, ""); err == nil {
+		t.Error("Ожидалась ошибка для поврежденного изображения")
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"calc_example/internal/model"
+)
+
+// resolveAttribution дополняет UTM-метки из адреса посадочной страницы
+// и определяет источник заявки, если форма его не передала
+func resolveAttribution(attribution *model.Attribution) {
+	attribution.Source = strings.TrimSpace(attribution.Source)
+
+	if landing, err := url.Parse(strings.TrimSpace(attribution.LandingPage)); err == nil {
+		query := landing.Query()
+		for param, field := range map[string]*string{
+			"utm_source":   &attribution.UTMSource,
+			"utm_medium":   &attribution.UTMMedium,
+			"utm_campaign": &attribution.UTMCampaign,
+			"utm_term":     &attribution.UTMTerm,
+			"utm_content":  &attribution.UTMContent,
+		} {
+			if *field == "" {
+				*field = query.Get(param)
+			}
+		}
+	}
+
+	if attribution.Source == "" {
+		attribution.Source = attribution.UTMSource
+	}
+	if attribution.Source == "" {
+		if referrer, err := url.Parse(attribution.Referrer); err == nil && referrer.Hostname() != "" {
+			attribution.Source = strings.TrimPrefix(referrer.Hostname(), "www.")
+		}
+	}
+	if attribution.Source == "" {
+		attribution.Source = model.DefaultLeadSource
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"calc_example/internal/model"
+)
+
+// Audit Service
+func (s *Service) GetIssueHistory(id uint) ([]model.AuditEntry, error) {
+	return s.repo.GetIssueHistory(id)
+}
+
+// audit записывает в журнал действие над заявкой целиком
+func (s *Service) audit(issueID uint, action string) error {
+	return s.repo.CreateAuditEntries([]model.AuditEntry{{
+		IssueID: issueID,
+		Action:  action,
+	}})
+}
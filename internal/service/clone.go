@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+
+	"calc_example/internal/model"
+)
+
+// CloneIssue создает новую заявку по образцу существующей: копируются контакты,
+// описание товара и габариты, статус и менеджер не переносятся. Поля из
+// overrides (документ JSON Merge Patch) заменяют скопированные значения.
+func (s *Service) CloneIssue(id uint, overrides []byte) (*model.IssueResponse, error) {
+	original, err := s.getIssue(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchDoc interface{} = map[string]interface{}{}
+	if len(overrides) > 0 {
+		if err := json.Unmarshal(overrides, &patchDoc); err != nil {
+			return nil, ErrInvalidPatch
+		}
+		if _, ok := patchDoc.(map[string]interface{}); !ok {
+			return nil, ErrInvalidPatch
+		}
+	}
+
+	doc, err := toDocument(cloneRequest(original))
+	if err != nil {
+		return nil, err
+	}
+	merged, err := json.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	var req model.CreateIssueRequest
+	if err := decodeStrict(merged, &req); err != nil {
+		return nil, err
+	}
+	if err := validateIssue(&req, &req.PreferredContactMethod, &req.ContactInfo); err != nil {
+		return nil, err
+	}
+
+	issue := newIssue(&req)
+	issue.ClonedFromID = &original.ID
+	return s.createIssue(issue)
+}
+
+// cloneRequest возвращает данные заявки, которые переносятся в копию
+func cloneRequest(issue *model.Issue) *model.CreateIssueRequest {
+	return &model.CreateIssueRequest{
+		FullName:               issue.FullName,
+		ContactInfo:            issue.ContactInfo,
+		PreferredContactMethod: issue.PreferredContactMethod,
+		HasChinaExperience:     issue.HasChinaExperience,
+		HasSupplierContacts:    issue.HasSupplierContacts,
+		ProductDescription:     issue.ProductDescription,
+		ExistingProductLinks:   issue.ExistingProductLinks,
+		Volume:                 issue.Volume,
+		Weight:                 issue.Weight,
+		Density:                issue.Density,
+		PreviousInvoiceFile:    issue.PreviousInvoiceFile,
+		ExpectedDeliveryDate:   issue.ExpectedDeliveryDate,
+		Attribution:            model.Attribution{Source: issue.Source},
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCloneIssue(t *testing.T) {
+	service := newTestService(t)
+
+	req := newTestIssueRequest()
+	volume, weight := 2.0, 500.0
+	req.Volume, req.Weight = &volume, &weight
+	original, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if _, err := service.PatchIssue(original.ID, []byte(`{"status": "quoted", "assignee": "Анна"}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+
+	clone, err := service.CloneIssue(original.ID, []byte(`{"weight": 800, "expectedDeliveryDate": "2025-03-01"}`))
+	if err != nil {
+		t.Fatalf("Ошибка копирования заявки: %v", err)
+	}
+	if clone.ID == original.ID || clone.ClonedFromID == nil || *clone.ClonedFromID != original.ID {
+		t.Errorf("Ожидалась новая заявка, связанная с исходной, получено %+v", clone)
+	}
+	if clone.ContactInfo != original.ContactInfo || clone.ProductDescription != original.ProductDescription {
+		t.Errorf("Контакты и товар должны копироваться, получено %+v", clone)
+	}
+	if clone.Status != "open" || clone.Assignee != "" {
+		t.Errorf("Копия должна начинаться с нового статуса, получено %s %q", clone.Status, clone.Assignee)
+	}
+	if clone.Weight == nil || *clone.Weight != 800 || clone.Density == nil || *clone.Density != 400 {
+		t.Errorf("Ожидались замененный вес и пересчитанная плотность, получено %v %v", clone.Weight, clone.Density)
+	}
+	if clone.ExpectedDeliveryDate != "2025-03-01" {
+		t.Errorf("Ожидалась новая дата доставки, получено %s", clone.ExpectedDeliveryDate)
+	}
+	if clone.DuplicateOfID != nil {
+		t.Errorf("Копия не должна помечаться как повторная заявка")
+	}
+	if clone.CustomerID == nil || *clone.CustomerID != *original.CustomerID {
+		t.Errorf("Копия должна относиться к тому же клиенту")
+	}
+
+	var validationErr *ValidationError
+	if _, err := service.CloneIssue(original.ID, []byte(`{"status": "closed"}`)); !errors.As(err, &validationErr) {
+		t.Errorf("Ожидалась ошибка неизвестного поля, получено %v", err)
+	}
+	if _, err := service.CloneIssue(999, nil); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия заявки, получено %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"calc_example/internal/model"
+)
+
+// contactMethodAliases - названия способов связи, которые присылает форма
+// на сайте и старые клиенты API
+var contactMethodAliases = map[string]string{
+	"phone":    model.ContactMethodPhone,
+	"телефон":  model.ContactMethodPhone,
+	"звонок":   model.ContactMethodPhone,
+	"telegram": model.ContactMethodTelegram,
+	"телеграм": model.ContactMethodTelegram,
+	"tg":       model.ContactMethodTelegram,
+	"whatsapp": model.ContactMethodWhatsApp,
+	"ватсап":   model.ContactMethodWhatsApp,
+	"вотсап":   model.ContactMethodWhatsApp,
+	"email":    model.ContactMethodEmail,
+	"e-mail":   model.ContactMethodEmail,
+	"почта":    model.ContactMethodEmail,
+	"wechat":   model.ContactMethodWeChat,
+	"вичат":    model.ContactMethodWeChat,
+}
+
+var (
+	telegramHandle = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{4,31}$`)
+	wechatID       = regexp.MustCompile(`^[a-zA-Z][-_a-zA-Z0-9]{5,19}$`)
+)
+
+// normalizeContact проверяет способ связи и приводит контакт к виду,
+// принятому для этого способа: телефоны - E.164, email - нижний регистр,
+// Telegram - @handle. Ошибки добавляются в errs по полям.
+func normalizeContact(method, contact *string, errs *ValidationError) {
+	normalized, ok := contactMethodAliases[strings.ToLower(strings.TrimSpace(*method))]
+	if !ok {
+		errs.add("preferredContactMethod", "допустимые значения: phone telegram whatsapp email wechat")
+		return
+	}
+	*method = normalized
+
+	value := strings.TrimSpace(*contact)
+	var valid bool
+	switch normalized {
+	case model.ContactMethodPhone, model.ContactMethodWhatsApp:
+		value, valid = normalizePhone(value)
+		if !valid {
+			errs.add("contactInfo", "ожидается номер телефона в международном формате, например +79991234567")
+			return
+		}
+	case model.ContactMethodEmail:
+		value, valid = normalizeEmail(value)
+		if !valid {
+			errs.add("contactInfo", "ожидается адрес электронной почты")
+			return
+		}
+	case model.ContactMethodTelegram:
+		value, valid = normalizeTelegram(value)
+		if !valid {
+			errs.add("contactInfo", "ожидается имя пользователя Telegram (@handle) или номер телефона")
+			return
+		}
+	case model.ContactMethodWeChat:
+		if phone, ok := normalizePhone(value); ok {
+			value = phone
+		} else if !wechatID.MatchString(value) {
+			errs.add("contactInfo", "ожидается WeChat ID или номер телефона")
+			return
+		}
+	}
+	*contact = value
+}
+
+// normalizePhone приводит номер к формату E.164
+func normalizePhone(value string) (string, bool) {
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r):
+			digits.WriteRune(r)
+		case strings.ContainsRune("+-() .", r):
+		default:
+			return "", false
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case len(number) == 11 && number[0] == '8' && !strings.HasPrefix(value, "+"):
+		// Российский номер, записанный через 8
+		number = "7" + number[1:]
+	case len(number) == 10 && number[0] == '9' && !strings.HasPrefix(value, "+"):
+		// Российский мобильный номер без кода страны
+		number = "7" + number
+	}
+
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", false
+	}
+	return "+" + number, true
+}
+
+func normalizeEmail(value string) (string, bool) {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || address.Name != "" {
+		return "", false
+	}
+	at := strings.LastIndex(address.Address, "@")
+	if !strings.Contains(address.Address[at+1:], ".") {
+		return "", false
+	}
+	return strings.ToLower(address.Address), true
+}
+
+func normalizeTelegram(value string) (string, bool) {
+	handle := value
+	for _, prefix := range []string{"https://", "http://", "t.me/", "telegram.me/", "@"} {
+		handle = strings.TrimPrefix(handle, prefix)
+	}
+	if telegramHandle.MatchString(handle) {
+		return "@" + strings.ToLower(handle), true
+	}
+	return normalizePhone(value)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+func TestNormalizeContact(t *testing.T) {
+	tests := []struct {
+		method, contact       string
+		wantMethod, wantValue string
+		wantField             string
+	}{
+		{method: "Телефон", contact: "8 (999) 123-45-67", wantMethod: "phone", wantValue: "+79991234567"},
+		{method: "whatsapp", contact: "+86 138 0013 8000", wantMethod: "whatsapp", wantValue: "+8613800138000"},
+		{method: "email", contact: "Ivan.Petrov@Mail.RU", wantMethod: "email", wantValue: "ivan.petrov@mail.ru"},
+		{method: "telegram", contact: "https://t.me/Ivan_Petrov", wantMethod: "telegram", wantValue: "@ivan_petrov"},
+		{method: "telegram", contact: "@ivan_petrov", wantMethod: "telegram", wantValue: "@ivan_petrov"},
+		{method: "telegram", contact: "+7 999 123 45 67", wantMethod: "telegram", wantValue: "+79991234567"},
+		{method: "wechat", contact: "wxid_abc123", wantMethod: "wechat", wantValue: "wxid_abc123"},
+		{method: "phone", contact: "позвоните мне", wantField: "contactInfo"},
+		{method: "email", contact: "ivan@localhost", wantField: "contactInfo"},
+		{method: "telegram", contact: "@abc", wantField: "contactInfo"},
+		{method: "голубиная почта", contact: "+79991234567", wantField: "preferredContactMethod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.contact, func(t *testing.T) {
+			method, contact := tt.method, tt.contact
+			errs := &ValidationError{}
+			normalizeContact(&method, &contact, errs)
+
+			if tt.wantField != "" {
+				if _, ok := errs.Fields[tt.wantField]; !ok {
+					t.Errorf("Ожидалась ошибка поля %s, получено %v", tt.wantField, errs.Fields)
+				}
+				return
+			}
+			if len(errs.Fields) > 0 {
+				t.Fatalf("Неожиданные ошибки: %v", errs.Fields)
+			}
+			if method != tt.wantMethod || contact != tt.wantValue {
+				t.Errorf("Ожидалось %s %s, получено %s %s", tt.wantMethod, tt.wantValue, method, contact)
+			}
+		})
+	}
+}
+
+func TestCreateIssueFieldErrors(t *testing.T) {
+	service := newTestService(t)
+
+	req := newTestIssueRequest()
+	req.FullName = ""
+	req.PreferredContactMethod = "email"
+	req.ContactInfo = "не почта"
+
+	_, err := service.CreateIssue(req)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+	}
+	for _, field := range []string{"fullName", "contactInfo"} {
+		if _, ok := validationErr.Fields[field]; !ok {
+			t.Errorf("Ожидалась ошибка поля %s, получено %v", field, validationErr.Fields)
+		}
+	}
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if issue.PreferredContactMethod != model.ContactMethodPhone || issue.ContactInfo != "+79991234567" {
+		t.Errorf("Ожидался нормализованный телефон, получено %s %s", issue.PreferredContactMethod, issue.ContactInfo)
+	}
+}
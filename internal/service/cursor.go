@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+var errInvalidCursor = errors.New("неверный курсор")
+
+// cursorToken - содержимое курсора до кодирования
+type cursorToken struct {
+	CreatedAt int64 `json:"t"`
+	ID        uint  `json:"id"`
+	Before    bool  `json:"b,omitempty"`
+}
+
+// encodeCursor кодирует позицию списка заявок в непрозрачную строку
+func encodeCursor(cursor model.IssueCursor) string {
+	data, _ := json.Marshal(cursorToken{
+		CreatedAt: cursor.CreatedAt.UnixNano(),
+		ID:        cursor.ID,
+		Before:    cursor.Before,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает курсор, полученный от encodeCursor. Время
+// возвращается в UTC, как оно хранится в базе
+func decodeCursor(value string) (*model.IssueCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ID == 0 {
+		return nil, errInvalidCursor
+	}
+	return &model.IssueCursor{
+		CreatedAt: time.Unix(0, token.CreatedAt).UTC(),
+		ID:        token.ID,
+		Before:    token.Before,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+
+	"calc_example/internal/model"
+	"calc_example/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrCustomerNotFound = errors.New("клиент не найден")
+
+// Customer Service
+func (s *Service) GetCustomerCard(id uint) (*model.CustomerCard, error) {
+	customer, err := s.repo.GetCustomerByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetCustomerStats(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CustomerCard{Customer: *customer, Stats: *stats}, nil
+}
+
+func (s *Service) GetCustomerIssues(id uint) ([]model.IssueResponse, error) {
+	if _, err := s.repo.GetCustomerByID(id); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCustomerNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	issues, err := s.repo.GetCustomerIssues(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []model.IssueResponse
+	for i := range issues {
+		responses = append(responses, *s.toIssueResponse(&issues[i]))
+	}
+
+	return responses, nil
+}
+
+// LinkCustomers связывает с клиентами заявки, созданные до появления клиентов.
+// Возвращает количество связанных заявок.
+func (s *Service) LinkCustomers() (int, error) {
+	issues, err := s.repo.GetIssuesWithoutCustomer()
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		issue.ContactKey = contactKey(issue.ContactInfo)
+		if err := linkCustomer(s.repo, issue); err != nil {
+			return i, err
+		}
+	}
+
+	return len(issues), nil
+}
+
+// linkCustomer связывает сохраненную заявку с клиентом по ее контакту,
+// создавая клиента при необходимости
+func linkCustomer(repo *repository.Repository, issue *model.Issue) error {
+	customerID, err := repo.LinkIssueCustomer(issue.ID, newCustomer(issue), func(customer *model.Customer) {
+		updateCustomer(customer, issue)
+	})
+	if err != nil {
+		return err
+	}
+	issue.CustomerID = &customerID
+	return nil
+}
+
+// newCustomer возвращает карточку нового клиента по данным заявки
+func newCustomer(issue *model.Issue) *model.Customer {
+	return &model.Customer{
+		FullName:               issue.FullName,
+		ContactInfo:            issue.ContactInfo,
+		ContactKey:             issue.ContactKey,
+		PreferredContactMethod: issue.PreferredContactMethod,
+		HasChinaExperience:     issue.HasChinaExperience,
+		HasSupplierContacts:    issue.HasSupplierContacts,
+	}
+}
+
+// updateCustomer дополняет карточку существующего клиента данными из заявки
+func updateCustomer(customer *model.Customer, issue *model.Issue) {
+	// Опыт клиента накапливается: однажды работавший с Китаем остается опытным
+	customer.HasChinaExperience = customer.HasChinaExperience || issue.HasChinaExperience
+	customer.HasSupplierContacts = customer.HasSupplierContacts || issue.HasSupplierContacts
+	if len([]rune(issue.FullName)) > len([]rune(customer.FullName)) {
+		customer.FullName = issue.FullName
+	}
+	if issue.PreferredContactMethod != "" {
+		customer.PreferredContactMethod = issue.PreferredContactMethod
+	}
+}
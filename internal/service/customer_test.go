@@ -0,0 +1,134 @@
+package service
+
+import (
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+func TestCustomerMatching(t *testing.T) {
+	service := newTestService(t)
+
+	first, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if first.CustomerID == nil {
+		t.Fatalf("Заявка должна быть связана с клиентом")
+	}
+
+	req := newTestIssueRequest()
+	req.ContactInfo = "8 (999) 123-45-67"
+	req.HasChinaExperience = false
+	req.HasSupplierContacts = true
+	second, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if second.CustomerID == nil || *second.CustomerID != *first.CustomerID {
+		t.Fatalf("Заявки с одним номером должны относиться к одному клиенту: %v и %v", first.CustomerID, second.CustomerID)
+	}
+
+	amount := 150000.0
+	volume := 2.5
+	if _, err := service.PatchIssue(first.ID, []byte(`{"status": "closed", "quoteAmount": 150000, "volume": 2.5}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+
+	card, err := service.GetCustomerCard(*first.CustomerID)
+	if err != nil {
+		t.Fatalf("Ошибка получения клиента: %v", err)
+	}
+	if !card.HasChinaExperience || !card.HasSupplierContacts {
+		t.Errorf("Опыт клиента должен накапливаться по всем заявкам, получено %+v", card.Customer)
+	}
+	if card.Stats.IssuesCount != 2 || card.Stats.WonCount != 1 {
+		t.Errorf("Ожидалось 2 заявки и 1 успешная, получено %+v", card.Stats)
+	}
+	if card.Stats.Revenue != amount || card.Stats.Volume != volume {
+		t.Errorf("Ожидались выручка %v и объем %v, получено %+v", amount, volume, card.Stats)
+	}
+
+	issues, err := service.GetCustomerIssues(*first.CustomerID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявок клиента: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Errorf("Ожидалось 2 заявки клиента, получено %d", len(issues))
+	}
+
+	if _, err := service.GetCustomerCard(999); err != ErrCustomerNotFound {
+		t.Errorf("Ожидалась ошибка ErrCustomerNotFound, получено %v", err)
+	}
+}
+
+func TestCustomerRelinkOnContactChange(t *testing.T) {
+	service := newTestService(t)
+
+	first, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	second, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	patched, err := service.PatchIssue(second.ID, []byte(`{"contactInfo": "+7-916-000-00-00"}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if patched.CustomerID == nil || *patched.CustomerID == *first.CustomerID {
+		t.Fatalf("С новым контактом заявка должна перейти к другому клиенту, получено %v", patched.CustomerID)
+	}
+
+	card, err := service.GetCustomerCard(*first.CustomerID)
+	if err != nil {
+		t.Fatalf("Ошибка получения клиента: %v", err)
+	}
+	if card.Stats.IssuesCount != 1 {
+		t.Errorf("У прежнего клиента должна остаться одна заявка, получено %d", card.Stats.IssuesCount)
+	}
+
+	// Возврат контакта возвращает заявку прежнему клиенту
+	patched, err = service.PatchIssue(second.ID, []byte(`{"contactInfo": "8 999 123 45 67"}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if patched.CustomerID == nil || *patched.CustomerID != *first.CustomerID {
+		t.Errorf("Ожидался клиент %d, получено %v", *first.CustomerID, patched.CustomerID)
+	}
+}
+
+func TestLinkCustomersStoresContactKey(t *testing.T) {
+	service := newTestService(t)
+
+	// Заявка, созданная до появления клиентов, без клиента и ключа контакта
+	legacy := &model.Issue{
+		FullName:               "Петр Петров",
+		ContactInfo:            "+79991234567",
+		PreferredContactMethod: model.ContactMethodPhone,
+		ProductDescription:     "Мебель",
+		ExpectedDeliveryDate:   "2024-12-01",
+		Status:                 model.StatusOpen,
+	}
+	if err := service.repo.CreateIssue(legacy); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	linked, err := service.LinkCustomers()
+	if err != nil {
+		t.Fatalf("Ошибка связывания заявок: %v", err)
+	}
+	if linked != 1 {
+		t.Fatalf("Ожидалась одна связанная заявка, получено %d", linked)
+	}
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if issue.DuplicateOfID == nil || *issue.DuplicateOfID != legacy.ID {
+		t.Errorf("Ожидался повтор старой заявки %d по контакту, получено %v", legacy.ID, issue.DuplicateOfID)
+	}
+}
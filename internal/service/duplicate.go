@@ -0,0 +1,118 @@
+package service
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"calc_example/internal/model"
+)
+
+// maxSimilarityRunes - сколько первых символов строк сравнивается
+// в similarity, чтобы длинные описания не замедляли создание заявки
+const maxSimilarityRunes = 200
+
+// findDuplicate ищет среди недавних заявок заявку того же клиента:
+// с тем же контактом или с очень похожими именем и товаром. По имени
+// и товару сравниваются только последние duplicate.Candidates заявок.
+// Возвращает ID исходной заявки цепочки повторов или nil.
+func (s *Service) findDuplicate(issue *model.Issue, now time.Time) (*uint, error) {
+	candidates, err := s.repo.GetDuplicateCandidates(now.Add(-s.duplicate.Window), issue.ContactKey, s.duplicate.Candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range candidates {
+		candidate := &candidates[i]
+		if !s.isDuplicate(issue, candidate) {
+			continue
+		}
+
+		// Все повторы ссылаются на первую заявку клиента
+		if candidate.DuplicateOfID != nil {
+			return candidate.DuplicateOfID, nil
+		}
+		return &candidate.ID, nil
+	}
+
+	return nil, nil
+}
+
+func (s *Service) isDuplicate(issue, candidate *model.Issue) bool {
+	if issue.ContactKey != "" && issue.ContactKey == candidate.ContactKey {
+		return true
+	}
+
+	return similarity(issue.FullName, candidate.FullName) >= s.duplicate.Similarity &&
+		similarity(issue.ProductDescription, candidate.ProductDescription) >= s.duplicate.Similarity
+}
+
+// contactKey приводит контакт к виду для сравнения: номер телефона
+// оставляет только цифрами, остальные контакты - в нижнем регистре без пробелов
+func contactKey(contact string) string {
+	contact = strings.TrimSpace(contact)
+
+	var digits strings.Builder
+	phone := true
+	for _, r := range contact {
+		switch {
+		case unicode.IsDigit(r):
+			digits.WriteRune(r)
+		case strings.ContainsRune("+-() .", r):
+		default:
+			phone = false
+		}
+	}
+
+	if phone && digits.Len() >= 7 {
+		key := digits.String()
+		// Российские номера: 8XXXXXXXXXX и +7XXXXXXXXXX - один номер
+		if len(key) == 11 && key[0] == '8' {
+			key = "7" + key[1:]
+		}
+		return key
+	}
+
+	return strings.TrimPrefix(strings.ToLower(strings.Join(strings.Fields(contact), "")), "@")
+}
+
+// similarity возвращает похожесть строк от 0 до 1 на основе расстояния
+// Левенштейна. Сравниваются первые maxSimilarityRunes символов
+func similarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.Join(strings.Fields(a), " ")))
+	rb := []rune(strings.ToLower(strings.Join(strings.Fields(b), " ")))
+	ra = ra[:min(len(ra), maxSimilarityRunes)]
+	rb = rb[:min(len(rb), maxSimilarityRunes)]
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContactKey(t *testing.T) {
+	tests := map[string]string{
+		"+7 (999) 123-45-67": "79991234567",
+		"8-999-123-45-67":    "79991234567",
+		" Ivan@Mail.RU ":     "ivan@mail.ru",
+		"@Ivan_Petrov":       "ivan_petrov",
+	}
+
+	for contact, want := range tests {
+		if got := contactKey(contact); got != want {
+			t.Errorf("contactKey(%q): ожидалось %q, получено %q", contact, want, got)
+		}
+	}
+}
+
+func TestCreateIssueDetectsDuplicate(t *testing.T) {
+	service := newTestService(t)
+
+	original, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if original.DuplicateOfID != nil {
+		t.Fatalf("Первая заявка не должна быть повтором")
+	}
+
+	// Тот же номер в другом формате
+	req := newTestIssueRequest()
+	req.FullName = "Петр"
+	req.ProductDescription = "Мебель"
+	req.ContactInfo = "8 999 123 45 67"
+	byContact, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if byContact.DuplicateOfID == nil || *byContact.DuplicateOfID != original.ID {
+		t.Errorf("Ожидалась ссылка на заявку %d, получено %v", original.ID, byContact.DuplicateOfID)
+	}
+
+	// Другой контакт, но почти те же имя и товар
+	req = newTestIssueRequest()
+	req.FullName = "Иван Иванов "
+	req.ProductDescription = "Электронные компоненты."
+	req.PreferredContactMethod = "email"
+	req.ContactInfo = "ivan@example.com"
+	bySimilarity, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if bySimilarity.DuplicateOfID == nil || *bySimilarity.DuplicateOfID != original.ID {
+		t.Errorf("Ожидалась ссылка на заявку %d, получено %v", original.ID, bySimilarity.DuplicateOfID)
+	}
+
+	req = newTestIssueRequest()
+	req.FullName = "Анна Смирнова"
+	req.ContactInfo = "+7-916-000-00-00"
+	other, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if other.DuplicateOfID != nil {
+		t.Errorf("Заявка другого клиента не должна быть повтором, получено %v", *other.DuplicateOfID)
+	}
+}
+
+func TestFindDuplicateCandidates(t *testing.T) {
+	service := newTestService(t)
+	service.duplicate.Candidates = 1
+
+	original, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	req := newTestIssueRequest()
+	req.FullName = "Анна Смирнова"
+	req.ProductDescription = "Мебель"
+	req.ContactInfo = "+7-916-000-00-00"
+	if _, err := service.CreateIssue(req); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Заявка с тем же контактом находится и за пределами последних заявок
+	req = newTestIssueRequest()
+	req.FullName = "Петр"
+	req.ProductDescription = "Одежда"
+	byContact, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if byContact.DuplicateOfID == nil || *byContact.DuplicateOfID != original.ID {
+		t.Errorf("Ожидалась ссылка на заявку %d, получено %v", original.ID, byContact.DuplicateOfID)
+	}
+
+	// По имени и товару сравнивается только последняя заявка
+	req = newTestIssueRequest()
+	req.PreferredContactMethod = "email"
+	req.ContactInfo = "ivan@example.com"
+	bySimilarity, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if bySimilarity.DuplicateOfID != nil {
+		t.Errorf("Заявка вне последних не должна сравниваться по имени, получено %v", *bySimilarity.DuplicateOfID)
+	}
+}
+
+func TestSimilarityLongText(t *testing.T) {
+	prefix := strings.Repeat("электронные компоненты ", 20)
+	if got := similarity(prefix+"резисторы", prefix+"конденсаторы"); got != 1 {
+		t.Errorf("Ожидалось сравнение первых %d символов, получено %v", maxSimilarityRunes, got)
+	}
+}
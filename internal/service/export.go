@@ -0,0 +1,179 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"calc_example/internal/export"
+	"calc_example/internal/model"
+)
+
+// Языки заголовков выгрузки
+const (
+	ExportLangRU = "ru"
+	ExportLangEN = "en"
+)
+
+// exportBatchSize - сколько заявок читается из базы за раз при выгрузке
+var exportBatchSize = 500
+
+// exportColumn - столбец выгрузки заявок
+type exportColumn struct {
+	key   string
+	ru    string
+	en    string
+	value func(issue *model.Issue, lang string) interface{}
+}
+
+// exportColumns - столбцы выгрузки в порядке по умолчанию
+var exportColumns = []exportColumn{
+	{"id", "Номер", "ID", func(i *model.Issue, _ string) interface{} { return i.ID }},
+	{"createdAt", "Создана", "Created", func(i *model.Issue, _ string) interface{} { return i.CreatedAt }},
+	{"status", "Статус", "Status", func(i *model.Issue, lang string) interface{} { return exportStatus(i.Status, lang) }},
+	{"fullName", "ФИО", "Full name", func(i *model.Issue, _ string) interface{} { return i.FullName }},
+	{"contactInfo", "Контакты", "Contact", func(i *model.Issue, _ string) interface{} { return i.ContactInfo }},
+	{"preferredContactMethod", "Способ связи", "Contact method", func(i *model.Issue, _ string) interface{} { return i.PreferredContactMethod }},
+	{"hasChinaExperience", "Опыт с Китаем", "China experience", func(i *model.Issue, lang string) interface{} { return exportBool(i.HasChinaExperience, lang) }},
+	{"hasSupplierContacts", "Контакты поставщика", "Supplier contacts", func(i *model.Issue, lang string) interface{} { return exportBool(i.HasSupplierContacts, lang) }},
+	{"productDescription", "Товар", "Product", func(i *model.Issue, _ string) interface{} { return i.ProductDescription }},
+	{"existingProductLinks", "Ссылки на товары", "Product links", func(i *model.Issue, _ string) interface{} { return i.ExistingProductLinks }},
+	{"volume", "Объем, м³", "Volume, m³", func(i *model.Issue, _ string) interface{} { return exportFloat(i.Volume) }},
+	{"weight", "Вес, кг", "Weight, kg", func(i *model.Issue, _ string) interface{} { return exportFloat(i.Weight) }},
+	{"density", "Плотность, кг/м³", "Density, kg/m³", func(i *model.Issue, _ string) interface{} { return exportFloat(i.Density) }},
+	{"expectedDeliveryDate", "Срок доставки", "Delivery date", func(i *model.Issue, _ string) interface{} { return i.ExpectedDeliveryDate }},
+	{"assignee", "Менеджер", "Assignee", func(i *model.Issue, _ string) interface{} { return i.Assignee }},
+	{"score", "Оценка", "Score", func(i *model.Issue, _ string) interface{} { return i.Score }},
+	{"tags", "Теги", "Tags", func(i *model.Issue, _ string) interface{} { return exportTags(i.Tags) }},
+	{"source", "Источник", "Source", func(i *model.Issue, _ string) interface{} { return i.Source }},
+	{"utmCampaign", "Кампания", "Campaign", func(i *model.Issue, _ string) interface{} { return i.UTMCampaign }},
+	{"quoteAmount", "Сумма предложения", "Quote amount", func(i *model.Issue, _ string) interface{} { return exportFloat(i.QuoteAmount) }},
+	{"lossReason", "Причина проигрыша", "Loss reason", func(i *model.Issue, _ string) interface{} { return i.LossReason }},
+	{"lossNote", "Комментарий к проигрышу", "Loss note", func(i *model.Issue, _ string) interface{} { return i.LossNote }},
+	{"supplierName", "Поставщик", "Supplier", func(i *model.Issue, _ string) interface{} { return i.SupplierName }},
+	{"currency", "Валюта", "Currency", func(i *model.Issue, _ string) interface{} { return i.Currency }},
+	{"declaredValue", "Стоимость по инвойсу", "Declared value", func(i *model.Issue, _ string) interface{} { return exportFloat(i.DeclaredValue) }},
+	{"updatedAt", "Изменена", "Updated", func(i *model.Issue, _ string) interface{} { return i.UpdatedAt }},
+}
+
+// exportStatusNames - названия статусов заявки в выгрузке на русском
+var exportStatusNames = map[string]string{
+	model.StatusOpen:      "Новая",
+	model.StatusContacted: "Связались",
+	model.StatusQuoted:    "Отправлено предложение",
+	model.StatusClosed:    "Закрыта",
+	model.StatusLost:      "Проиграна",
+}
+
+// IssueExport - подготовленная выгрузка заявок
+type IssueExport struct {
+	service *Service
+	filter  model.IssueFilter
+	columns []exportColumn
+	lang    string
+}
+
+// ExportIssues готовит выгрузку заявок, подходящих под фильтр, со столбцами
+// columns (ключи как в JSON заявки, по умолчанию все) и заголовками на
+// языке lang. Страница и курсор фильтра не учитываются
+func (s *Service) ExportIssues(filter model.IssueFilter, columns []string, lang string) (*IssueExport, error) {
+	filter.Page, filter.Limit, filter.Cursor = 0, 0, ""
+	if _, err := normalizeIssueFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	validation := &ValidationError{}
+	if lang == "" {
+		lang = ExportLangRU
+	}
+	if lang != ExportLangRU && lang != ExportLangEN {
+		validation.add("lang", "допустимые значения: ru en")
+	}
+
+	selected := exportColumns
+	if len(columns) > 0 {
+		selected = nil
+		for _, key := range columns {
+			key = strings.TrimSpace(key)
+			i := slices.IndexFunc(exportColumns, func(column exportColumn) bool { return column.key == key })
+			if i < 0 {
+				validation.add("columns", "неизвестный столбец "+key)
+				break
+			}
+			selected = append(selected, exportColumns[i])
+		}
+	}
+
+	if len(validation.Fields) > 0 {
+		return nil, validation
+	}
+	return &IssueExport{service: s, filter: filter, columns: selected, lang: lang}, nil
+}
+
+// Title возвращает название листа выгрузки
+func (e *IssueExport) Title() string {
+	if e.lang == ExportLangEN {
+		return "Issues"
+	}
+	return "Заявки"
+}
+
+// WriteTo записывает в w строку заголовков и по строке на каждую заявку.
+// Заявки читаются из базы пачками и сразу записываются
+func (e *IssueExport) WriteTo(w export.Writer) error {
+	header := make([]interface{}, len(e.columns))
+	for i, column := range e.columns {
+		if e.lang == ExportLangEN {
+			header[i] = column.en
+		} else {
+			header[i] = column.ru
+		}
+	}
+	if err := w.WriteRow(header); err != nil {
+		return err
+	}
+
+	return e.service.repo.EachIssue(e.filter, exportBatchSize, func(issue *model.Issue) error {
+		row := make([]interface{}, len(e.columns))
+		for i, column := range e.columns {
+			row[i] = column.value(issue, e.lang)
+		}
+		return w.WriteRow(row)
+	})
+}
+
+func exportStatus(status, lang string) string {
+	if name, ok := exportStatusNames[status]; ok && lang == ExportLangRU {
+		return name
+	}
+	return status
+}
+
+func exportBool(value bool, lang string) string {
+	switch {
+	case lang == ExportLangEN && value:
+		return "yes"
+	case lang == ExportLangEN:
+		return "no"
+	case value:
+		return "да"
+	default:
+		return "нет"
+	}
+}
+
+func exportTags(tags []model.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// exportFloat возвращает nil для незаполненного значения, чтобы ячейка
+// осталась пустой
+func exportFloat(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
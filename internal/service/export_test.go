@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"calc_example/internal/export"
+	"calc_example/internal/model"
+)
+
+func TestExportIssues(t *testing.T) {
+	service := newTestService(t)
+
+	var ids []uint
+	for _, name := range []string{"Иван Иванов", "Петр Петров", "Анна Смирнова", "Олег Сидоров"} {
+		req := newTestIssueRequest()
+		req.FullName = name
+		created, err := service.CreateIssue(req)
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+	if _, err := service.PatchIssue(ids[1], []byte(`{"status": "contacted", "volume": 1.5}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if _, err := service.PatchIssue(ids[3], []byte(`{"status": "closed"}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+
+	issues, err := service.ExportIssues(model.IssueFilter{
+		Statuses: []string{model.StatusOpen, model.StatusContacted},
+		SortBy:   model.IssueSortFullName,
+		SortAsc:  true,
+	}, []string{"fullName", "status", "volume", "hasChinaExperience"}, "")
+	if err != nil {
+		t.Fatalf("Ошибка подготовки выгрузки: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := export.NewCSV(&buf)
+	if err != nil {
+		t.Fatalf("Ошибка создания выгрузки: %v", err)
+	}
+	if err := issues.WriteTo(w); err != nil {
+		t.Fatalf("Ошибка выгрузки: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Ошибка выгрузки: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("Ошибка чтения CSV: %v", err)
+	}
+	want := [][]string{
+		{"ФИО", "Статус", "Объем, м³", "Опыт с Китаем"},
+		{"Анна Смирнова", "Новая", "", "да"},
+		{"Иван Иванов", "Новая", "", "да"},
+		{"Петр Петров", "Связались", "1.5", "да"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Ожидалось %d строк, получено %q", len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("Строка %d: ожидалось %q, получено %q", i, want[i], rows[i])
+		}
+	}
+}
+
+func TestExportIssuesInvalid(t *testing.T) {
+	service := newTestService(t)
+
+	tests := []struct {
+		name    string
+		filter  model.IssueFilter
+		columns []string
+		lang    string
+		field   string
+	}{
+		{"неизвестный столбец", model.IssueFilter{}, []string{"fullName", "password"}, "", "columns"},
+		{"неизвестный язык", model.IssueFilter{}, nil, "de", "lang"},
+		{"неверный фильтр", model.IssueFilter{Statuses: []string{"archived"}}, nil, "", "status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ExportIssues(tt.filter, tt.columns, tt.lang)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+			}
+			if _, ok := validationErr.Fields[tt.field]; !ok {
+				t.Errorf("Ожидалась ошибка в поле %s, получено %v", tt.field, validationErr.Fields)
+			}
+		})
+	}
+}
+
+// hookWriter запоминает строки выгрузки и вызывает onRow после каждой
+type hookWriter struct {
+	rows  [][]interface{}
+	onRow func()
+}
+
+func (w *hookWriter) WriteRow(values []interface{}) error {
+	w.rows = append(w.rows, values)
+	w.onRow()
+	return nil
+}
+
+func (w *hookWriter) Close() error { return nil }
+
+func TestExportIssuesBatches(t *testing.T) {
+	service := newTestService(t)
+
+	batchSize := exportBatchSize
+	exportBatchSize = 2
+	t.Cleanup(func() { exportBatchSize = batchSize })
+
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		created, err := service.CreateIssue(newTestIssueRequest())
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+
+	issues, err := service.ExportIssues(model.IssueFilter{
+		Statuses: []string{model.StatusOpen},
+		SortBy:   model.IssueSortScore,
+		SortAsc:  true,
+	}, []string{"id"}, "")
+	if err != nil {
+		t.Fatalf("Ошибка подготовки выгрузки: %v", err)
+	}
+
+	// Выгруженные заявки уходят из фильтра, а новые не попадают в выгрузку
+	w := &hookWriter{}
+	w.onRow = func() {
+		row := w.rows[len(w.rows)-1]
+		id, ok := row[0].(uint)
+		if !ok {
+			return
+		}
+		if _, err := service.PatchIssue(id, []byte(`{"status": "contacted"}`), 0); err != nil {
+			t.Fatalf("Ошибка изменения заявки: %v", err)
+		}
+		if _, err := service.CreateIssue(newTestIssueRequest()); err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+	}
+	if err := issues.WriteTo(w); err != nil {
+		t.Fatalf("Ошибка выгрузки: %v", err)
+	}
+
+	if len(w.rows) != len(ids)+1 {
+		t.Fatalf("Ожидалось %d строк, получено %v", len(ids)+1, w.rows)
+	}
+	for i, id := range ids {
+		if w.rows[i+1][0] != id {
+			t.Errorf("Строка %d: ожидалась заявка %d, получено %v", i+1, id, w.rows[i+1][0])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"calc_example/internal/model"
+)
+
+// issueSortFields - допустимые поля сортировки списка заявок
+var issueSortFields = []string{
+	model.IssueSortCreatedAt,
+	model.IssueSortUpdatedAt,
+	model.IssueSortScore,
+	model.IssueSortFullName,
+	model.IssueSortStatus,
+}
+
+// normalizeIssueFilter проверяет фильтр списка заявок, подставляет
+// значения по умолчанию и разбирает курсор страницы, если он задан
+func normalizeIssueFilter(filter *model.IssueFilter) (*model.IssueCursor, error) {
+	result := &ValidationError{}
+
+	filter.Tags = normalizeTags(filter.Tags)
+	filter.Assignee = strings.TrimSpace(filter.Assignee)
+
+	statuses := make([]string, 0, len(filter.Statuses))
+	for _, status := range filter.Statuses {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+		if !slices.Contains(model.IssueStatuses, status) {
+			result.add("status", "допустимые значения: "+strings.Join(model.IssueStatuses, " "))
+			break
+		}
+		statuses = append(statuses, status)
+	}
+	filter.Statuses = statuses
+
+	if filter.SortBy == "" {
+		filter.SortBy = model.IssueSortCreatedAt
+	}
+	if !slices.Contains(issueSortFields, filter.SortBy) {
+		result.add("sort", "допустимые значения: "+strings.Join(issueSortFields, " "))
+	}
+
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Page < 0 {
+		result.add("page", "значение должно быть не меньше 1")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = model.DefaultIssueLimit
+	}
+	if filter.Limit < 0 || filter.Limit > model.MaxIssueLimit {
+		result.add("limit", fmt.Sprintf("значение должно быть от 1 до %d", model.MaxIssueLimit))
+	}
+
+	if from, to := filter.Created.From, filter.Created.To; from != nil && to != nil && !from.Before(*to) {
+		result.add("from", "начало периода должно быть раньше конца")
+	}
+
+	var cursor *model.IssueCursor
+	if filter.Cursor != "" {
+		var err error
+		switch {
+		case filter.SortBy != model.IssueSortCreatedAt:
+			result.add("cursor", "курсор доступен только при сортировке по дате создания")
+		case filter.Page > 1:
+			result.add("cursor", "курсор нельзя использовать вместе с номером страницы")
+		default:
+			if cursor, err = decodeCursor(filter.Cursor); err != nil {
+				result.add("cursor", err.Error())
+			}
+		}
+	}
+
+	if len(result.Fields) > 0 {
+		return nil, result
+	}
+	return cursor, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+	"calc_example/pkg/database"
+)
+
+func TestGetAllIssuesFilter(t *testing.T) {
+	service := newTestService(t)
+
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		req := newTestIssueRequest()
+		req.HasChinaExperience = i != 0
+		created, err := service.CreateIssue(req)
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+	if _, err := service.PatchIssue(ids[1], []byte(`{"status": "contacted", "assignee": "Анна"}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+
+	noExperience := false
+	// Границы с западным смещением текстом меньше UTC, с восточным - больше
+	now := time.Now().UTC()
+	after := now.Add(time.Minute).In(time.FixedZone("EST", -5*60*60))
+	before := now.Add(-time.Minute).In(time.FixedZone("MSK", 3*60*60))
+	tests := []struct {
+		name   string
+		filter model.IssueFilter
+		want   []uint
+		total  int64
+	}{
+		{"по умолчанию новые первыми", model.IssueFilter{}, []uint{ids[2], ids[1], ids[0]}, 3},
+		{"по возрастанию", model.IssueFilter{SortAsc: true}, []uint{ids[0], ids[1], ids[2]}, 3},
+		{"по статусу", model.IssueFilter{Statuses: []string{"Contacted"}}, []uint{ids[1]}, 1},
+		{"без менеджера", model.IssueFilter{Assignee: model.IssueAssigneeNone}, []uint{ids[2], ids[0]}, 2},
+		{"по менеджеру", model.IssueFilter{Assignee: "Анна"}, []uint{ids[1]}, 1},
+		{"без опыта с Китаем", model.IssueFilter{HasChinaExperience: &noExperience}, []uint{ids[0]}, 1},
+		{"вторая страница", model.IssueFilter{Page: 2, Limit: 2}, []uint{ids[0]}, 3},
+		{"созданные после границы со смещением", model.IssueFilter{Created: model.ReportPeriod{From: &after}}, nil, 0},
+		{"созданные до границы со смещением", model.IssueFilter{Created: model.ReportPeriod{To: &before}}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.GetAllIssues(tt.filter)
+			if err != nil {
+				t.Fatalf("Ошибка получения заявок: %v", err)
+			}
+			if page.Total != tt.total {
+				t.Errorf("Ожидалось всего %d заявок, получено %d", tt.total, page.Total)
+			}
+			var got []uint
+			for _, issue := range page.Items {
+				got = append(got, issue.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Ожидались заявки %v, получены %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Ожидались заявки %v, получены %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllIssuesInvalidFilter(t *testing.T) {
+	service := newTestService(t)
+
+	tests := []struct {
+		name   string
+		filter model.IssueFilter
+		field  string
+	}{
+		{"неизвестный статус", model.IssueFilter{Statuses: []string{"archived"}}, "status"},
+		{"неизвестная сортировка", model.IssueFilter{SortBy: "phone"}, "sort"},
+		{"слишком большая страница", model.IssueFilter{Limit: model.MaxIssueLimit + 1}, "limit"},
+		{"отрицательная страница", model.IssueFilter{Page: -1}, "page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetAllIssues(tt.filter)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+			}
+			if _, ok := validationErr.Fields[tt.field]; !ok {
+				t.Errorf("Ожидалась ошибка в поле %s, получено %v", tt.field, validationErr.Fields)
+			}
+		})
+	}
+}
+
+func TestGetAllIssuesCursor(t *testing.T) {
+	service := newTestService(t)
+
+	create := func() uint {
+		created, err := service.CreateIssue(newTestIssueRequest())
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		return created.ID
+	}
+	var ids []uint
+	for i := 0; i < 5; i++ {
+		ids = append(ids, create())
+	}
+
+	fetch := func(cursor string) *model.IssuePage {
+		t.Helper()
+		page, err := service.GetAllIssues(model.IssueFilter{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("Ошибка получения заявок: %v", err)
+		}
+		return page
+	}
+	expect := func(page *model.IssuePage, want ...uint) {
+		t.Helper()
+		if len(page.Items) != len(want) {
+			t.Fatalf("Ожидались заявки %v, получено %d", want, len(page.Items))
+		}
+		for i, issue := range page.Items {
+			if issue.ID != want[i] {
+				t.Fatalf("Ожидались заявки %v, на позиции %d получена %d", want, i, issue.ID)
+			}
+		}
+	}
+
+	first := fetch("")
+	expect(first, ids[4], ids[3])
+	if first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("Первая страница должна иметь только курсор next: %+v", first)
+	}
+
+	// Новая заявка не сдвигает следующие страницы
+	newest := create()
+
+	second := fetch(first.NextCursor)
+	expect(second, ids[2], ids[1])
+	last := fetch(second.NextCursor)
+	expect(last, ids[0])
+	if last.NextCursor != "" {
+		t.Errorf("У последней страницы не должно быть курсора next")
+	}
+
+	back := fetch(second.PrevCursor)
+	expect(back, ids[4], ids[3])
+	if back.PrevCursor == "" {
+		t.Fatalf("Ожидался курсор prev на новую заявку")
+	}
+	expect(fetch(back.PrevCursor), newest)
+}
+
+func TestGetAllIssuesInvalidCursor(t *testing.T) {
+	service := newTestService(t)
+
+	tests := []struct {
+		name   string
+		filter model.IssueFilter
+	}{
+		{"мусор", model.IssueFilter{Cursor: "не курсор"}},
+		{"сортировка по оценке", model.IssueFilter{Cursor: encodeCursor(model.IssueCursor{ID: 1}), SortBy: model.IssueSortScore}},
+		{"вместе с номером страницы", model.IssueFilter{Cursor: encodeCursor(model.IssueCursor{ID: 1}), Page: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetAllIssues(tt.filter)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+			}
+			if _, ok := validationErr.Fields["cursor"]; !ok {
+				t.Errorf("Ожидалась ошибка в поле cursor, получено %v", validationErr.Fields)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorUTC(t *testing.T) {
+	createdAt := time.Date(2024, 3, 10, 1, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	cursor, err := decodeCursor(encodeCursor(model.IssueCursor{CreatedAt: createdAt, ID: 7}))
+	if err != nil {
+		t.Fatalf("Ошибка разбора курсора: %v", err)
+	}
+	if cursor.CreatedAt.Location() != time.UTC || !cursor.CreatedAt.Equal(createdAt) || cursor.ID != 7 {
+		t.Errorf("Ожидалось время %v в UTC, получено %v", createdAt, cursor.CreatedAt)
+	}
+}
+
+func TestIssuesSortedByUpdatedAtWithOffsets(t *testing.T) {
+	service, db := newTestServiceDB(t)
+
+	batchSize := exportBatchSize
+	exportBatchSize = 1
+	t.Cleanup(func() { exportBatchSize = batchSize })
+
+	// Время изменения старых заявок со смещением: как текст порядок
+	// обратный, в UTC - 09:00, 10:00 и 11:00
+	var ids []uint
+	for _, updatedAt := range []string{"2024-03-10 12:00:00+03:00", "2024-03-10 10:00:00+00:00", "2024-03-10 06:00:00-05:00"} {
+		created, err := service.CreateIssue(newTestIssueRequest())
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		if err := db.Exec("UPDATE issues SET updated_at = ? WHERE id = ?", updatedAt, created.ID).Error; err != nil {
+			t.Fatalf("Ошибка подготовки заявки: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	filter := model.IssueFilter{SortBy: model.IssueSortUpdatedAt, SortAsc: true}
+
+	var listed []uint
+	for page := 1; page <= 2; page++ {
+		filter.Page, filter.Limit = page, 2
+		result, err := service.GetAllIssues(filter)
+		if err != nil {
+			t.Fatalf("Ошибка получения заявок: %v", err)
+		}
+		for _, issue := range result.Items {
+			listed = append(listed, issue.ID)
+		}
+	}
+	if !slices.Equal(listed, ids) {
+		t.Errorf("Ожидался порядок %v, получено %v", ids, listed)
+	}
+
+	// Выгрузка читает заявки по одной, начиная каждую пачку после
+	// времени изменения предыдущей заявки
+	filter.Page, filter.Limit = 0, 0
+	issues, err := service.ExportIssues(filter, []string{"id"}, "")
+	if err != nil {
+		t.Fatalf("Ошибка подготовки выгрузки: %v", err)
+	}
+	w := &hookWriter{onRow: func() {}}
+	if err := issues.WriteTo(w); err != nil {
+		t.Fatalf("Ошибка выгрузки: %v", err)
+	}
+	var exported []uint
+	for _, row := range w.rows[1:] {
+		exported = append(exported, row[0].(uint))
+	}
+	if !slices.Equal(exported, ids) {
+		t.Errorf("Ожидалась выгрузка %v, получено %v", ids, exported)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"calc_example/internal/invoice"
+	"calc_example/internal/model"
+)
+
+// Invoice Service
+
+// extractInvoice разбирает инвойс прошлой поставки - загруженный файл
+// с именем name (поле previousInvoiceFile). Остальные файлы и файлы
+// в карантине не разбираются. Ошибки разбора не мешают загрузке, и тогда
+// возвращается nil
+func extractInvoice(uploads []inspectedUpload, name string) *invoice.Invoice {
+	if name == "" {
+		return nil
+	}
+	for _, upload := range uploads {
+		if upload.QuarantineReason != "" || upload.FileName != sanitizeFileName(name) {
+			continue
+		}
+		inv, err := invoice.Parse(upload.Data, upload.MimeType)
+		if err == nil && !inv.Empty() {
+			return inv
+		}
+		return nil
+	}
+	return nil
+}
+
+// applyInvoice заполняет данные инвойса заявки извлеченными из файла
+// значениями. Их еще предстоит подтвердить менеджеру
+func applyInvoice(issue *model.Issue, inv *invoice.Invoice) {
+	issue.DeclaredInvoice = model.DeclaredInvoice{
+		SupplierName:  inv.Supplier,
+		Currency:      inv.Currency,
+		DeclaredValue: inv.Total,
+		InvoiceStatus: model.InvoiceStatusExtracted,
+	}
+
+	issue.LineItems = make([]model.LineItem, 0, len(inv.Lines))
+	for i, line := range inv.Lines {
+		issue.LineItems = append(issue.LineItems, model.LineItem{
+			Position:    i + 1,
+			Description: line.Description,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.Amount,
+		})
+	}
+}
+
+// fillInvoice извлекает данные инвойса из добавленного к заявке инвойса
+// прошлой поставки с именем name, если менеджер еще не подтвердил данные инвойса
+func (s *Service) fillInvoice(issue *model.Issue, uploads []inspectedUpload, name string) error {
+	if issue.InvoiceStatus == model.InvoiceStatusConfirmed {
+		return nil
+	}
+
+	inv := extractInvoice(uploads, name)
+	if inv == nil {
+		return nil
+	}
+
+	applyInvoice(issue, inv)
+	return s.repo.SaveInvoice(issue, issue.LineItems)
+}
+
+// ConfirmInvoice подтверждает данные инвойса заявки. Поля из patch
+// (документ JSON Merge Patch) исправляют извлеченные значения, позиции
+// в patch заменяют позиции заявки целиком. Если заявленная стоимость
+// не указана, она считается как сумма позиций. Если version не равна нулю,
+// подтверждается только эта версия заявки
+func (s *Service) ConfirmInvoice(id uint, patch []byte, version uint) (*model.IssueResponse, error) {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchDoc interface{} = map[string]interface{}{}
+	if len(strings.TrimSpace(string(patch))) > 0 {
+		if err := json.Unmarshal(patch, &patchDoc); err != nil {
+			return nil, ErrInvalidPatch
+		}
+		if _, ok := patchDoc.(map[string]interface{}); !ok {
+			return nil, ErrInvalidPatch
+		}
+	}
+
+	doc, err := toDocument(invoiceFields(issue))
+	if err != nil {
+		return nil, err
+	}
+	merged, err := json.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	var fields model.InvoiceFields
+	if err := decodeStrict(merged, &fields); err != nil {
+		return nil, err
+	}
+	if err := validate(&fields); err != nil {
+		return nil, err
+	}
+
+	applyInvoiceFields(issue, &fields)
+	if err := s.repo.SaveInvoice(issue, issue.LineItems); err != nil {
+		return nil, err
+	}
+
+	entry := model.AuditEntry{IssueID: id, Action: model.AuditActionInvoiceConfirmed}
+	if issue.DeclaredValue != nil {
+		entry.NewValue = strconv.FormatFloat(*issue.DeclaredValue, 'f', -1, 64)
+	}
+	if err := s.repo.CreateAuditEntries([]model.AuditEntry{entry}); err != nil {
+		return nil, err
+	}
+
+	return s.toIssueResponse(issue), nil
+}
+
+func invoiceFields(issue *model.Issue) *model.InvoiceFields {
+	items := make([]model.LineItemFields, 0, len(issue.LineItems))
+	for _, item := range issue.LineItems {
+		items = append(items, model.LineItemFields{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      item.Amount,
+		})
+	}
+
+	return &model.InvoiceFields{
+		SupplierName:  issue.SupplierName,
+		Currency:      issue.Currency,
+		DeclaredValue: issue.DeclaredValue,
+		LineItems:     items,
+	}
+}
+
+func applyInvoiceFields(issue *model.Issue, fields *model.InvoiceFields) {
+	issue.LineItems = make([]model.LineItem, 0, len(fields.LineItems))
+	var total float64
+	complete := len(fields.LineItems) > 0
+	for i, item := range fields.LineItems {
+		amount := item.Amount
+		if amount == nil && item.Quantity != nil && item.UnitPrice != nil {
+			value := *item.Quantity * *item.UnitPrice
+			amount = &value
+		}
+		if amount != nil {
+			total += *amount
+		} else {
+			complete = false
+		}
+
+		issue.LineItems = append(issue.LineItems, model.LineItem{
+			Position:    i + 1,
+			Description: strings.TrimSpace(item.Description),
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Amount:      amount,
+		})
+	}
+
+	issue.SupplierName = strings.TrimSpace(fields.SupplierName)
+	issue.Currency = strings.ToUpper(strings.TrimSpace(fields.Currency))
+	issue.DeclaredValue = fields.DeclaredValue
+	if issue.DeclaredValue == nil && complete {
+		issue.DeclaredValue = &total
+	}
+	issue.InvoiceStatus = model.InvoiceStatusConfirmed
+}
+
+// lineItems возвращает позиции инвойса, пустой список вместо nil
+func lineItems(items []model.LineItem) []model.LineItem {
+	if items == nil {
+		return []model.LineItem{}
+	}
+	return items
+}
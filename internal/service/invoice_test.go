@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+// testInvoiceCSV - инвойс поставщика в CSV с разделителем ";"
+var testInvoiceCSV = []byte("Поставщик:;Yiwu Trading Co\nВалюта:;CNY\n\n" +
+	"Наименование;Кол-во;Цена;Сумма\n" +
+	"Светильник;100;12,5;1250\n" +
+	"Блок питания;20;5;100\n" +
+	"Итого;;;1350\n")
+
+func TestCreateIssueExtractsInvoice(t *testing.T) {
+	service := newTestService(t)
+
+	req := newTestIssueRequest()
+	req.PreviousInvoiceFile = "invoice.csv"
+	issue, err := service.CreateIssueWithFiles(req, []model.FileUpload{
+		{FileName: "photo.png", Data: testPNG},
+		{FileName: "invoice.csv", Data: testInvoiceCSV},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	if issue.SupplierName != "Yiwu Trading Co" || issue.Currency != "CNY" {
+		t.Errorf("Неверные поставщик и валюта: %q %q", issue.SupplierName, issue.Currency)
+	}
+	if issue.DeclaredValue == nil || *issue.DeclaredValue != 1350 {
+		t.Errorf("Ожидалась заявленная стоимость 1350, получено %v", issue.DeclaredValue)
+	}
+	if issue.InvoiceStatus != model.InvoiceStatusExtracted {
+		t.Errorf("Ожидался статус %s, получено %q", model.InvoiceStatusExtracted, issue.InvoiceStatus)
+	}
+
+	stored, err := service.GetIssueByID(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if len(stored.LineItems) != 2 || stored.LineItems[0].Description != "Светильник" || *stored.LineItems[1].Amount != 100 {
+		t.Errorf("Неверные позиции: %+v", stored.LineItems)
+	}
+}
+
+func TestConfirmInvoice(t *testing.T) {
+	service := newTestService(t)
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if issue.InvoiceStatus != "" || len(issue.LineItems) != 0 {
+		t.Fatalf("У заявки без инвойса не должно быть данных инвойса: %+v", issue.DeclaredInvoice)
+	}
+
+	if _, err := service.AddAttachments(issue.ID, []model.FileUpload{{FileName: "invoice.csv", Data: testInvoiceCSV}}, "invoice.csv"); err != nil {
+		t.Fatalf("Ошибка загрузки инвойса: %v", err)
+	}
+	extracted, err := service.GetIssueByID(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if extracted.InvoiceStatus != model.InvoiceStatusExtracted || len(extracted.LineItems) != 2 {
+		t.Fatalf("Данные инвойса не извлечены из вложения: %+v", extracted)
+	}
+
+	// Обычное вложение не перезаписывает извлеченные данные
+	other := bytes.ReplaceAll(testInvoiceCSV, []byte("Yiwu Trading Co"), []byte("Ningbo Export Co"))
+	if _, err := service.AddAttachments(issue.ID, []model.FileUpload{{FileName: "other.csv", Data: other}}, ""); err != nil {
+		t.Fatalf("Ошибка загрузки файла: %v", err)
+	}
+	extracted, err = service.GetIssueByID(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if extracted.SupplierName != "Yiwu Trading Co" {
+		t.Fatalf("Данные инвойса перезаписаны обычным вложением: %+v", extracted.DeclaredInvoice)
+	}
+
+	var validationErr *ValidationError
+	_, err = service.ConfirmInvoice(issue.ID, []byte(`{"lineItems": [{"description": "", "quantity": -1}]}`), 0)
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Ожидалась ошибка проверки, получено %v", err)
+	}
+	if _, ok := validationErr.Fields["lineItems[0].quantity"]; !ok {
+		t.Errorf("Ожидалась ошибка по количеству позиции, получено %v", validationErr.Fields)
+	}
+
+	confirmed, err := service.ConfirmInvoice(issue.ID, []byte(`{
+		"supplierName": "Yiwu Lighting Co",
+		"declaredValue": null,
+		"lineItems": [{"description": "Светильник", "quantity": 120, "unitPrice": 12.5}]
+	}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка подтверждения инвойса: %v", err)
+	}
+	if confirmed.InvoiceStatus != model.InvoiceStatusConfirmed || confirmed.SupplierName != "Yiwu Lighting Co" || confirmed.Currency != "CNY" {
+		t.Errorf("Неверные данные инвойса: %+v", confirmed.DeclaredInvoice)
+	}
+	if confirmed.DeclaredValue == nil || *confirmed.DeclaredValue != 1500 {
+		t.Errorf("Ожидалась стоимость по позициям 1500, получено %v", confirmed.DeclaredValue)
+	}
+	if len(confirmed.LineItems) != 1 || *confirmed.LineItems[0].Amount != 1500 {
+		t.Errorf("Неверные позиции: %+v", confirmed.LineItems)
+	}
+
+	// Подтвержденные данные не перезаписываются новыми файлами
+	if _, err := service.AddAttachments(issue.ID, []model.FileUpload{{FileName: "invoice2.csv", Data: testInvoiceCSV}}, "invoice2.csv"); err != nil {
+		t.Fatalf("Ошибка загрузки инвойса: %v", err)
+	}
+	stored, err := service.GetIssueByID(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if stored.SupplierName != "Yiwu Lighting Co" || len(stored.LineItems) != 1 {
+		t.Errorf("Подтвержденный инвойс перезаписан: %+v", stored.DeclaredInvoice)
+	}
+
+	history, err := service.GetIssueHistory(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения журнала: %v", err)
+	}
+	if len(history) == 0 || history[len(history)-1].Action != model.AuditActionInvoiceConfirmed || history[len(history)-1].NewValue != "1500" {
+		t.Errorf("Подтверждение инвойса не записано в журнал: %+v", history)
+	}
+}
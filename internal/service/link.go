@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLinkNotFound = errors.New("связь не найдена")
+	ErrLinkExists   = errors.New("такая связь уже существует")
+	ErrLinkSelf     = errors.New("нельзя связать заявку с самой собой")
+)
+
+// Issue Link Service
+
+// CreateIssueLink связывает заявку id с другой заявкой связью указанного типа
+func (s *Service) CreateIssueLink(id uint, req *model.CreateIssueLinkRequest) (*model.IssueResponse, error) {
+	if id == req.IssueID {
+		return nil, ErrLinkSelf
+	}
+	if _, err := s.getIssue(id); err != nil {
+		return nil, err
+	}
+	if _, err := s.getIssue(req.IssueID); err != nil {
+		return nil, err
+	}
+
+	link := &model.IssueLink{IssueID: id, LinkedIssueID: req.IssueID, Type: req.Type}
+	exists, err := s.repo.IssueLinkExists(link)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrLinkExists
+	}
+
+	if err := s.repo.CreateIssueLink(link); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CreateAuditEntries([]model.AuditEntry{{
+		IssueID:  id,
+		Action:   model.AuditActionLinked,
+		Field:    link.Type,
+		NewValue: strconv.FormatUint(uint64(link.LinkedIssueID), 10),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetIssueByID(id)
+}
+
+// DeleteIssueLink удаляет связь заявки id, входящую или исходящую
+func (s *Service) DeleteIssueLink(id, linkID uint) (*model.IssueResponse, error) {
+	if _, err := s.getIssue(id); err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.GetIssueLinkByID(linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if link.IssueID != id && link.LinkedIssueID != id {
+		return nil, ErrLinkNotFound
+	}
+
+	if err := s.repo.DeleteIssueLink(link); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CreateAuditEntries([]model.AuditEntry{{
+		IssueID:  link.IssueID,
+		Action:   model.AuditActionUnlinked,
+		Field:    link.Type,
+		OldValue: strconv.FormatUint(uint64(link.LinkedIssueID), 10),
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetIssueByID(id)
+}
+
+// issueLinks объединяет исходящие и входящие связи заявки
+func issueLinks(issue *model.Issue) []model.IssueLinkView {
+	links := make([]model.IssueLinkView, 0, len(issue.Links)+len(issue.BackLinks))
+	for _, link := range issue.Links {
+		links = append(links, model.IssueLinkView{
+			ID:        link.ID,
+			Type:      link.Type,
+			IssueID:   link.LinkedIssueID,
+			Direction: model.LinkDirectionOutgoing,
+		})
+	}
+	for _, link := range issue.BackLinks {
+		links = append(links, model.IssueLinkView{
+			ID:        link.ID,
+			Type:      link.Type,
+			IssueID:   link.IssueID,
+			Direction: model.LinkDirectionIncoming,
+		})
+	}
+	return links
+}
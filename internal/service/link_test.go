@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+func TestIssueLinks(t *testing.T) {
+	service := newTestService(t)
+
+	first, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	second, err := service.CloneIssue(first.ID, nil)
+	if err != nil {
+		t.Fatalf("Ошибка копирования заявки: %v", err)
+	}
+	if len(second.Links) != 1 || second.Links[0].Type != model.LinkTypeRepeatOf || second.Links[0].IssueID != first.ID {
+		t.Fatalf("Ожидалась связь repeat-of с исходной заявкой, получено %+v", second.Links)
+	}
+
+	issue, err := service.CreateIssueLink(first.ID, &model.CreateIssueLinkRequest{Type: model.LinkTypeSplitFrom, IssueID: second.ID})
+	if err != nil {
+		t.Fatalf("Ошибка добавления связи: %v", err)
+	}
+	if len(issue.Links) != 2 {
+		t.Fatalf("Ожидались исходящая и входящая связи, получено %+v", issue.Links)
+	}
+	directions := map[string]string{}
+	for _, link := range issue.Links {
+		directions[link.Type] = link.Direction
+	}
+	if directions[model.LinkTypeSplitFrom] != model.LinkDirectionOutgoing || directions[model.LinkTypeRepeatOf] != model.LinkDirectionIncoming {
+		t.Errorf("Неверные направления связей: %+v", issue.Links)
+	}
+
+	req := &model.CreateIssueLinkRequest{Type: model.LinkTypeSplitFrom, IssueID: second.ID}
+	if _, err := service.CreateIssueLink(first.ID, req); !errors.Is(err, ErrLinkExists) {
+		t.Errorf("Ожидалась ошибка повторной связи, получено %v", err)
+	}
+	req.IssueID = first.ID
+	if _, err := service.CreateIssueLink(first.ID, req); !errors.Is(err, ErrLinkSelf) {
+		t.Errorf("Ожидалась ошибка связи с собой, получено %v", err)
+	}
+	req.IssueID = 999
+	if _, err := service.CreateIssueLink(first.ID, req); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия заявки, получено %v", err)
+	}
+
+	// Входящую связь можно удалить со стороны любой из заявок
+	issue, err = service.DeleteIssueLink(first.ID, second.Links[0].ID)
+	if err != nil {
+		t.Fatalf("Ошибка удаления связи: %v", err)
+	}
+	if len(issue.Links) != 1 || issue.Links[0].Type != model.LinkTypeSplitFrom {
+		t.Errorf("Ожидалась одна оставшаяся связь, получено %+v", issue.Links)
+	}
+	if _, err := service.DeleteIssueLink(first.ID, second.Links[0].ID); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия связи, получено %v", err)
+	}
+
+	history, err := service.GetIssueHistory(second.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения истории: %v", err)
+	}
+	if len(history) == 0 || history[len(history)-1].Action != model.AuditActionUnlinked {
+		t.Errorf("Удаление связи должно попадать в журнал, получено %+v", history)
+	}
+}
+
+func TestIssueLinksKeepColumns(t *testing.T) {
+	service := newTestService(t)
+
+	original, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	clone, err := service.CloneIssue(original.ID, nil)
+	if err != nil {
+		t.Fatalf("Ошибка копирования заявки: %v", err)
+	}
+
+	// Удаление автоматической связи снимает отметку о копии
+	issue, err := service.DeleteIssueLink(clone.ID, clone.Links[0].ID)
+	if err != nil {
+		t.Fatalf("Ошибка удаления связи: %v", err)
+	}
+	if issue.ClonedFromID != nil {
+		t.Errorf("Без связи repeat-of заявка не должна быть копией, получено %d", *issue.ClonedFromID)
+	}
+
+	// Связь, добавленная вручную, отмечает заявку как повтор
+	issue, err = service.CreateIssueLink(clone.ID, &model.CreateIssueLinkRequest{Type: model.LinkTypeDuplicateOf, IssueID: original.ID})
+	if err != nil {
+		t.Fatalf("Ошибка добавления связи: %v", err)
+	}
+	if issue.DuplicateOfID == nil || *issue.DuplicateOfID != original.ID {
+		t.Errorf("Ожидался повтор заявки %d, получено %v", original.ID, issue.DuplicateOfID)
+	}
+}
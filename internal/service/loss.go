@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLossReasonExists      = errors.New("причина проигрыша с таким кодом уже существует")
+	ErrInvalidReportInterval = errors.New("интервал отчета должен быть day, week или month")
+)
+
+// Loss Reason Service
+func (s *Service) GetAllLossReasons() ([]model.LossReason, error) {
+	return s.repo.GetAllLossReasons()
+}
+
+// CreateLossReason добавляет причину проигрыша в справочник
+func (s *Service) CreateLossReason(req *model.CreateLossReasonRequest) (*model.LossReason, error) {
+	code := normalizeLossCode(req.Code)
+
+	_, err := s.repo.GetLossReasonByCode(code)
+	if err == nil {
+		return nil, ErrLossReasonExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	reason := &model.LossReason{Code: code, Name: strings.TrimSpace(req.Name)}
+	if err := s.repo.CreateLossReason(reason); err != nil {
+		return nil, err
+	}
+	return reason, nil
+}
+
+// GetLossReasonReport строит отчет о причинах проигрыша по интервалам времени
+func (s *Service) GetLossReasonReport(period model.ReportPeriod, interval string) ([]model.LossReasonReportRow, error) {
+	if interval == "" {
+		interval = model.ReportIntervalMonth
+	}
+	switch interval {
+	case model.ReportIntervalDay, model.ReportIntervalWeek, model.ReportIntervalMonth:
+	default:
+		return nil, ErrInvalidReportInterval
+	}
+
+	return s.repo.GetLossReasonReport(period, interval)
+}
+
+// validateLoss проверяет, что для проигранной заявки выбрана причина из
+// справочника, и приводит код причины к виду, в котором он хранится
+func (s *Service) validateLoss(fields *model.IssueFields) error {
+	fields.LossReason = normalizeLossCode(fields.LossReason)
+	if fields.Status != model.StatusLost {
+		return nil
+	}
+
+	result := &ValidationError{}
+	code := fields.LossReason
+	if code == "" {
+		result.add("lossReason", "обязательное поле")
+		return result
+	}
+
+	_, err := s.repo.GetLossReasonByCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.add("lossReason", "неизвестная причина проигрыша")
+		return result
+	}
+	return err
+}
+
+// normalizeLossCode приводит код причины проигрыша к нижнему регистру
+func normalizeLossCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+func TestLoseIssue(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	for patch, message := range map[string]string{
+		`{"status": "lost"}`:                          "обязательное поле",
+		`{"status": "lost", "lossReason": "weather"}`: "неизвестная причина проигрыша",
+	} {
+		_, err := service.PatchIssue(created.ID, []byte(patch), 0)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields["lossReason"] != message {
+			t.Errorf("Патч %s: ожидалась ошибка %q, получено %v", patch, message, err)
+		}
+	}
+
+	issue, err := service.PatchIssue(created.ID, []byte(`{"status": "lost", "lossReason": " Price", "lossNote": "Нашли дешевле"}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if issue.LossReason != "price" || issue.LossNote != "Нашли дешевле" || issue.LostAt == nil {
+		t.Errorf("Ожидалась причина проигрыша, получено %+v", issue)
+	}
+
+	rows, err := service.GetLossReasonReport(model.ReportPeriod{}, "")
+	if err != nil {
+		t.Fatalf("Ошибка построения отчета: %v", err)
+	}
+	period := issue.LostAt.UTC().Format("2006-01")
+	if len(rows) != 1 || rows[0].Period != period || rows[0].Reason != "price" || rows[0].Name != "Цена" || rows[0].Count != 1 {
+		t.Errorf("Неверный отчет: %+v", rows)
+	}
+	if _, err := service.GetLossReasonReport(model.ReportPeriod{}, "year"); !errors.Is(err, ErrInvalidReportInterval) {
+		t.Errorf("Ожидалась ошибка интервала, получено %v", err)
+	}
+
+	// Граница с западным смещением текстом меньше времени проигрыша в UTC
+	from := time.Now().UTC().Add(time.Minute).In(time.FixedZone("EST", -5*60*60))
+	rows, err = service.GetLossReasonReport(model.ReportPeriod{From: &from}, "")
+	if err != nil {
+		t.Fatalf("Ошибка построения отчета: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Проигрыш вне периода попал в отчет: %+v", rows)
+	}
+
+	issue, err = service.PatchIssue(created.ID, []byte(`{"status": "open"}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if issue.LossReason != "" || issue.LostAt != nil {
+		t.Errorf("При возврате в работу причина проигрыша должна сбрасываться, получено %+v", issue)
+	}
+}
+
+func TestCreateLossReason(t *testing.T) {
+	service := newTestService(t)
+
+	reason, err := service.CreateLossReason(&model.CreateLossReasonRequest{Code: " Customs ", Name: "Растаможка"})
+	if err != nil {
+		t.Fatalf("Ошибка создания причины: %v", err)
+	}
+	if reason.Code != "customs" {
+		t.Errorf("Ожидался нормализованный код, получено %s", reason.Code)
+	}
+
+	if _, err := service.CreateLossReason(&model.CreateLossReasonRequest{Code: "price", Name: "Цена"}); !errors.Is(err, ErrLossReasonExists) {
+		t.Errorf("Ожидалась ошибка повторной причины, получено %v", err)
+	}
+
+	reasons, err := service.GetAllLossReasons()
+	if err != nil {
+		t.Fatalf("Ошибка получения причин: %v", err)
+	}
+	if len(reasons) != len(model.PredefinedLossReasons)+1 {
+		t.Errorf("Ожидалось %d причин, получено %d", len(model.PredefinedLossReasons)+1, len(reasons))
+	}
+}
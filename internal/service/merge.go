@@ -0,0 +1,292 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+var ErrMergeSelf = errors.New("заявку нельзя объединить саму с собой")
+
+// MergeConflictError - поля, в которых объединяемые заявки содержат разные
+// значения и для которых нужно явно указать, из какой заявки взять значение
+type MergeConflictError struct {
+	Fields map[string][]model.MergeCandidate
+}
+
+func (e *MergeConflictError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "конфликт значений при объединении заявок: " + strings.Join(names, ", ")
+}
+
+// Поля, в которых при объединении достаточно значения true хотя бы у одной заявки
+var mergeFlagFields = map[string]bool{
+	"hasChinaExperience":  true,
+	"hasSupplierContacts": true,
+}
+
+// Поля, значения которых имеют смысл только вместе: выбор одного из них
+// распространяется на связанное поле
+var mergeLinkedFields = map[string]string{
+	"contactInfo":            "preferredContactMethod",
+	"preferredContactMethod": "contactInfo",
+}
+
+// MergeIssues объединяет заявки-источники с заявкой targetID. Для каждого поля
+// берется наиболее полное непустое значение, при конфликте значение должно
+// быть выбрано явно. Теги, напоминания, журнал и позиции инвойса переносятся
+// в итоговую заявку, а источники перемещаются в корзину со ссылкой на нее.
+// Если version не равна нулю, объединение выполняется только с этой
+// версией итоговой заявки.
+func (s *Service) MergeIssues(targetID uint, req *model.MergeIssuesRequest, version uint) (*model.IssueResponse, error) {
+	target, err := s.getIssueVersion(targetID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []*model.Issue{target}
+	sources := make([]model.Issue, 0, len(req.SourceIDs))
+	seen := map[uint]bool{targetID: true}
+	for _, id := range req.SourceIDs {
+		if id == targetID {
+			return nil, ErrMergeSelf
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		source, err := s.getIssue(id)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, *source)
+		issues = append(issues, source)
+	}
+
+	before := issueFields(target)
+	merged, err := mergeFields(issues, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	merged.Status = target.Status
+	if err := validateIssue(merged, &merged.PreferredContactMethod, &merged.ContactInfo); err != nil {
+		return nil, err
+	}
+	if err := s.validateLoss(merged); err != nil {
+		return nil, err
+	}
+	links, err := parseProductLinks(merged.ExistingProductLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	applyIssueFields(target, merged, now)
+	target.ProductLinks = links
+	mergeInvoice(target, sources)
+	s.score(target, now)
+
+	entries, err := diffFields(target.ID, before, issueFields(target))
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		entries = append(entries,
+			model.AuditEntry{IssueID: target.ID, Action: model.AuditActionMerged, OldValue: strconv.FormatUint(uint64(source.ID), 10)},
+			model.AuditEntry{IssueID: source.ID, Action: model.AuditActionMergedInto, NewValue: strconv.FormatUint(uint64(target.ID), 10)},
+		)
+	}
+
+	if err := s.repo.MergeIssues(target, sources, entries); err != nil {
+		return nil, err
+	}
+
+	// С контактом источника итоговая заявка переходит к его клиенту
+	if target.ContactKey != contactKey(before.ContactInfo) {
+		if err := linkCustomer(s.repo, target); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetIssueByID(target.ID)
+}
+
+// mergeInvoice заполняет пустые данные инвойса итоговой заявки значениями
+// источников и добавляет позиции источников после ее позиций. Стоимость
+// берется только в той же валюте
+func mergeInvoice(target *model.Issue, sources []model.Issue) {
+	for _, source := range sources {
+		if target.SupplierName == "" {
+			target.SupplierName = source.SupplierName
+		}
+		if target.Currency == "" {
+			target.Currency = source.Currency
+		}
+		if target.DeclaredValue == nil && (source.Currency == "" || source.Currency == target.Currency) {
+			target.DeclaredValue = source.DeclaredValue
+		}
+		if target.InvoiceStatus == "" {
+			target.InvoiceStatus = source.InvoiceStatus
+		}
+		target.LineItems = append(target.LineItems, source.LineItems...)
+	}
+
+	for i := range target.LineItems {
+		target.LineItems[i].Position = i + 1
+	}
+}
+
+// mergeFields выбирает значение каждого редактируемого поля среди заявок.
+// Первая заявка в списке - итоговая.
+func mergeFields(issues []*model.Issue, choices map[string]uint) (*model.IssueFields, error) {
+	values := make([]map[string]json.RawMessage, len(issues))
+	for i, issue := range issues {
+		fields, err := rawFields(issueFields(issue))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = fields
+	}
+
+	result := make(map[string]json.RawMessage, len(values[0]))
+	validationErr := &ValidationError{}
+	conflictErr := &MergeConflictError{Fields: make(map[string][]model.MergeCandidate)}
+
+	selected := make(map[string]uint, len(choices))
+	for field, issueID := range choices {
+		if _, ok := values[0][field]; !ok || field == "status" {
+			validationErr.add(field, "поле не объединяется")
+		}
+		selected[field] = issueID
+	}
+	for field, issueID := range choices {
+		if linked, ok := mergeLinkedFields[field]; ok {
+			if _, chosen := choices[linked]; !chosen {
+				selected[linked] = issueID
+			}
+		}
+	}
+
+	for field := range values[0] {
+		// Статус итоговой заявки не меняется
+		if field == "status" {
+			continue
+		}
+
+		if issueID, ok := selected[field]; ok {
+			index := indexOfIssue(issues, issueID)
+			if index < 0 {
+				validationErr.add(field, fmt.Sprintf("заявка %d не участвует в объединении", issueID))
+				continue
+			}
+			result[field] = values[index][field]
+			continue
+		}
+
+		var candidates []model.MergeCandidate
+		for i, issue := range issues {
+			if value := values[i][field]; !isEmptyValue(value) && !containsCandidate(candidates, value) {
+				candidates = append(candidates, model.MergeCandidate{IssueID: issue.ID, Value: value})
+			}
+		}
+
+		switch {
+		case len(candidates) == 0:
+			result[field] = values[0][field]
+		case mergeFlagFields[field]:
+			result[field] = candidates[0].Value
+		default:
+			value, ok := richestValue(candidates)
+			if !ok {
+				conflictErr.Fields[field] = candidates
+				continue
+			}
+			result[field] = value
+		}
+	}
+
+	if len(validationErr.Fields) > 0 {
+		return nil, validationErr
+	}
+	if len(conflictErr.Fields) > 0 {
+		return nil, conflictErr
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var fields model.IssueFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return &fields, nil
+}
+
+// richestValue возвращает значение, которое включает в себя все остальные
+// (например, "Иван Иванов" вместо "Иван"). Если такого нет - это конфликт.
+func richestValue(candidates []model.MergeCandidate) (json.RawMessage, bool) {
+	if len(candidates) == 1 {
+		return candidates[0].Value, true
+	}
+
+	for _, candidate := range candidates {
+		var richest string
+		if json.Unmarshal(candidate.Value, &richest) != nil {
+			continue
+		}
+
+		containsAll := true
+		for _, other := range candidates {
+			var value string
+			if json.Unmarshal(other.Value, &value) != nil ||
+				!strings.Contains(strings.ToLower(richest), strings.ToLower(strings.TrimSpace(value))) {
+				containsAll = false
+				break
+			}
+		}
+		if containsAll {
+			return candidate.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+func isEmptyValue(value json.RawMessage) bool {
+	switch string(value) {
+	case "", "null", `""`, "false":
+		return true
+	}
+	return false
+}
+
+func containsCandidate(candidates []model.MergeCandidate, value json.RawMessage) bool {
+	for _, candidate := range candidates {
+		if bytes.Equal(candidate.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOfIssue(issues []*model.Issue, id uint) int {
+	for i, issue := range issues {
+		if issue.ID == id {
+			return i
+		}
+	}
+	return -1
+}
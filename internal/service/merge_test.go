@@ -0,0 +1,160 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+func TestMergeIssues(t *testing.T) {
+	service := newTestService(t)
+
+	target, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	volume := 3.0
+	req := newTestIssueRequest()
+	req.FullName = "Иван Иванов Петрович"
+	req.PreferredContactMethod = "email"
+	req.ContactInfo = "ivan@example.com"
+	req.HasSupplierContacts = true
+	req.Volume = &volume
+	req.ExistingProductLinks = ""
+	source, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if _, err := service.AddIssueTags(source.ID, []string{"срочно"}, 0); err != nil {
+		t.Fatalf("Ошибка добавления тегов: %v", err)
+	}
+	reminder, err := service.CreateReminder(source.ID, &model.CreateReminderRequest{RemindAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+
+	mergeReq := &model.MergeIssuesRequest{SourceIDs: []uint{source.ID}}
+
+	// Контакты различаются - нужен явный выбор
+	_, err = service.MergeIssues(target.ID, mergeReq, 0)
+	var conflictErr *MergeConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Ожидался конфликт, получено %v", err)
+	}
+	if conflictErr.Fields["contactInfo"] == nil || conflictErr.Fields["fullName"] != nil {
+		t.Fatalf("Ожидался конфликт по контакту, но не по имени, получено %v", conflictErr.Fields)
+	}
+
+	mergeReq.Fields = map[string]uint{"contactInfo": source.ID}
+	merged, err := service.MergeIssues(target.ID, mergeReq, 0)
+	if err != nil {
+		t.Fatalf("Ошибка объединения заявок: %v", err)
+	}
+
+	if merged.FullName != req.FullName {
+		t.Errorf("Ожидалось более полное имя %q, получено %q", req.FullName, merged.FullName)
+	}
+	if merged.ContactInfo != req.ContactInfo || merged.PreferredContactMethod != "email" {
+		t.Errorf("Ожидался выбранный контакт %q вместе со способом связи, получено %q (%s)", req.ContactInfo, merged.ContactInfo, merged.PreferredContactMethod)
+	}
+	if !merged.HasSupplierContacts || merged.Volume == nil || *merged.Volume != volume {
+		t.Errorf("Ожидались непустые значения из источника, получено %+v", merged)
+	}
+	if merged.ExistingProductLinks != target.ExistingProductLinks {
+		t.Errorf("Пустое значение источника не должно затирать поле, получено %q", merged.ExistingProductLinks)
+	}
+	if len(merged.Tags) != 1 || merged.Tags[0] != "срочно" {
+		t.Errorf("Ожидался перенос тегов, получено %v", merged.Tags)
+	}
+
+	reminders, err := service.GetIssueReminders(target.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения напоминаний: %v", err)
+	}
+	if len(reminders) != 1 || reminders[0].ID != reminder.ID {
+		t.Errorf("Ожидался перенос напоминания, получено %v", reminders)
+	}
+
+	trash, err := service.GetDeletedIssues()
+	if err != nil {
+		t.Fatalf("Ошибка получения корзины: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != source.ID || trash[0].MergedIntoID == nil || *trash[0].MergedIntoID != target.ID {
+		t.Errorf("Источник должен быть в корзине со ссылкой на итоговую заявку, получено %v", trash)
+	}
+
+	if _, err := service.MergeIssues(target.ID, &model.MergeIssuesRequest{SourceIDs: []uint{target.ID}}, 0); err != ErrMergeSelf {
+		t.Errorf("Ожидалась ошибка ErrMergeSelf, получено %v", err)
+	}
+}
+
+func TestMergeIssuesInvoice(t *testing.T) {
+	service := newTestService(t)
+
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		issue, err := service.CreateIssue(newTestIssueRequest())
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		ids = append(ids, issue.ID)
+	}
+	for _, id := range ids[1:] {
+		if _, err := service.AddAttachments(id, []model.FileUpload{{FileName: "invoice.csv", Data: testInvoiceCSV}}, "invoice.csv"); err != nil {
+			t.Fatalf("Ошибка загрузки инвойса: %v", err)
+		}
+	}
+
+	merged, err := service.MergeIssues(ids[0], &model.MergeIssuesRequest{SourceIDs: ids[1:]}, 0)
+	if err != nil {
+		t.Fatalf("Ошибка объединения заявок: %v", err)
+	}
+
+	if merged.SupplierName != "Yiwu Trading Co" || merged.Currency != "CNY" || merged.InvoiceStatus != model.InvoiceStatusExtracted {
+		t.Errorf("Ожидались данные инвойса источника, получено %+v", merged.DeclaredInvoice)
+	}
+	if merged.DeclaredValue == nil || *merged.DeclaredValue != 1350 {
+		t.Errorf("Ожидалась заявленная стоимость 1350, получено %v", merged.DeclaredValue)
+	}
+	if len(merged.LineItems) != 4 {
+		t.Fatalf("Ожидался перенос 4 позиций, получено %+v", merged.LineItems)
+	}
+	for i, item := range merged.LineItems {
+		if item.Position != i+1 {
+			t.Errorf("Позиция %d имеет номер %d", i+1, item.Position)
+		}
+	}
+	if merged.LineItems[2].Description != "Светильник" {
+		t.Errorf("Позиции второго источника должны идти после первого, получено %+v", merged.LineItems)
+	}
+}
+
+func TestMergeIssuesIntoDuplicate(t *testing.T) {
+	service := newTestService(t)
+
+	original, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	duplicate, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if duplicate.DuplicateOfID == nil || *duplicate.DuplicateOfID != original.ID {
+		t.Fatalf("Ожидался повтор заявки %d, получено %v", original.ID, duplicate.DuplicateOfID)
+	}
+
+	merged, err := service.MergeIssues(duplicate.ID, &model.MergeIssuesRequest{SourceIDs: []uint{original.ID}}, 0)
+	if err != nil {
+		t.Fatalf("Ошибка объединения заявок: %v", err)
+	}
+	if merged.DuplicateOfID != nil {
+		t.Errorf("Итоговая заявка не должна ссылаться на себя, получено %d", *merged.DuplicateOfID)
+	}
+	if len(merged.Links) != 0 {
+		t.Errorf("Связь между объединенными заявками должна удаляться, получено %+v", merged.Links)
+	}
+}
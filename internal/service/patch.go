@@ -0,0 +1,283 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"calc_example/internal/model"
+	"calc_example/internal/repository"
+)
+
+var ErrInvalidPatch = errors.New("тело запроса должно быть JSON-объектом")
+
+// PatchIssue применяет к заявке документ JSON Merge Patch (RFC 7396).
+// Изменения полей записываются в журнал, производные значения пересчитываются.
+// Если version не равна нулю, изменение применяется только к этой версии заявки.
+func (s *Service) PatchIssue(id uint, patch []byte, version uint) (*model.IssueResponse, error) {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return nil, ErrInvalidPatch
+	}
+	patchObj, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidPatch
+	}
+
+	before := issueFields(issue)
+	beforeDoc, err := toDocument(before)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(mergePatch(beforeDoc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeIssueFields(merged)
+	if err != nil {
+		return nil, err
+	}
+	// Прежняя плотность не подходит к новым объему и весу, если ее
+	// не передали вместе с ними
+	if !hasKey(patchObj, "density") && (hasKey(patchObj, "volume") || hasKey(patchObj, "weight")) {
+		after.Density = nil
+	}
+	if err := validateIssue(after, &after.PreferredContactMethod, &after.ContactInfo); err != nil {
+		return nil, err
+	}
+	if err := s.validateLoss(after); err != nil {
+		return nil, err
+	}
+	links, err := parseProductLinks(after.ExistingProductLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	applyIssueFields(issue, after, time.Now())
+	s.score(issue, time.Now())
+
+	changes, err := diffFields(id, before, issueFields(issue))
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return s.toIssueResponse(issue), nil
+	}
+
+	// Заявка, журнал и клиент сохраняются в одной транзакции, чтобы
+	// изменение не осталось без записи в журнале
+	err = s.repo.Transaction(func(repo *repository.Repository) error {
+		// Ссылки на товары пересохраняются только при изменении текста ссылок
+		if issue.ExistingProductLinks != before.ExistingProductLinks {
+			issue.ProductLinks = links
+			err = repo.SaveProductLinks(issue, links)
+		} else {
+			err = repo.UpdateIssue(issue)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := repo.CreateAuditEntries(changes); err != nil {
+			return err
+		}
+
+		// С новым контактом заявка переходит к клиенту с этим контактом
+		if issue.ContactKey != contactKey(before.ContactInfo) {
+			return linkCustomer(repo, issue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toIssueResponse(issue), nil
+}
+
+func issueFields(issue *model.Issue) *model.IssueFields {
+	return &model.IssueFields{
+		FullName:               issue.FullName,
+		ContactInfo:            issue.ContactInfo,
+		PreferredContactMethod: issue.PreferredContactMethod,
+		HasChinaExperience:     issue.HasChinaExperience,
+		HasSupplierContacts:    issue.HasSupplierContacts,
+		ProductDescription:     issue.ProductDescription,
+		ExistingProductLinks:   issue.ExistingProductLinks,
+		Volume:                 issue.Volume,
+		Weight:                 issue.Weight,
+		Density:                issue.Density,
+		PreviousInvoiceFile:    issue.PreviousInvoiceFile,
+		ExpectedDeliveryDate:   issue.ExpectedDeliveryDate,
+		Status:                 issue.Status,
+		Assignee:               issue.Assignee,
+		QuoteAmount:            issue.QuoteAmount,
+		LossReason:             issue.LossReason,
+		LossNote:               issue.LossNote,
+	}
+}
+
+func applyIssueFields(issue *model.Issue, fields *model.IssueFields, now time.Time) {
+	issue.FullName = fields.FullName
+	issue.ContactInfo = fields.ContactInfo
+	issue.PreferredContactMethod = fields.PreferredContactMethod
+	issue.HasChinaExperience = fields.HasChinaExperience
+	issue.HasSupplierContacts = fields.HasSupplierContacts
+	issue.ProductDescription = fields.ProductDescription
+	issue.ExistingProductLinks = fields.ExistingProductLinks
+	issue.Volume = fields.Volume
+	issue.Weight = fields.Weight
+	issue.Density = fields.Density
+	issue.PreviousInvoiceFile = fields.PreviousInvoiceFile
+	issue.ExpectedDeliveryDate = fields.ExpectedDeliveryDate
+	issue.Assignee = strings.TrimSpace(fields.Assignee)
+	issue.QuoteAmount = fields.QuoteAmount
+	issue.LossReason = normalizeLossCode(fields.LossReason)
+	issue.LossNote = strings.TrimSpace(fields.LossNote)
+	applyStatus(issue, fields.Status, now)
+	recalculate(issue)
+}
+
+// recalculate пересчитывает производные значения заявки. Плотность
+// считается по объему и весу, а без них остается указанной клиентом
+func recalculate(issue *model.Issue) {
+	issue.ContactKey = contactKey(issue.ContactInfo)
+
+	if issue.Volume != nil && issue.Weight != nil && *issue.Volume > 0 {
+		density := *issue.Weight / *issue.Volume
+		issue.Density = &density
+	}
+}
+
+func hasKey(obj map[string]interface{}, key string) bool {
+	_, ok := obj[key]
+	return ok
+}
+
+func decodeIssueFields(data []byte) (*model.IssueFields, error) {
+	var fields model.IssueFields
+	if err := decodeStrict(data, &fields); err != nil {
+		return nil, err
+	}
+	return &fields, nil
+}
+
+// decodeStrict разбирает документ в v, возвращая ошибки типов
+// и неизвестные поля как ValidationError
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			result := &ValidationError{}
+			result.add(typeErr.Field, "неверный тип значения")
+			return result
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			result := &ValidationError{}
+			result.add(strings.Trim(field, `"`), "поле не редактируется")
+			return result
+		}
+		return err
+	}
+	return nil
+}
+
+// mergePatch применяет patch к target по алгоритму RFC 7396
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = make(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+func toDocument(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// diffFields возвращает записи журнала для каждого изменившегося поля
+func diffFields(issueID uint, before, after *model.IssueFields) ([]model.AuditEntry, error) {
+	oldValues, err := rawFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newValues, err := rawFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(newValues))
+	for field := range newValues {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var entries []model.AuditEntry
+	for _, field := range fields {
+		if bytes.Equal(oldValues[field], newValues[field]) {
+			continue
+		}
+		entries = append(entries, model.AuditEntry{
+			IssueID:  issueID,
+			Action:   model.AuditActionUpdated,
+			Field:    field,
+			OldValue: auditValue(oldValues[field]),
+			NewValue: auditValue(newValues[field]),
+		})
+	}
+	return entries, nil
+}
+
+func rawFields(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// auditValue приводит JSON-значение к читаемому виду для журнала
+func auditValue(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
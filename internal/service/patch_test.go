@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+func TestPatchIssue(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	issue, err := service.PatchIssue(created.ID, []byte(`{"contactInfo": "+7-999-000-00-00", "volume": 2, "weight": 500}`), created.Version)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if issue.ContactInfo != "+79990000000" {
+		t.Errorf("Ожидался новый контакт, получен %s", issue.ContactInfo)
+	}
+	if issue.FullName != created.FullName {
+		t.Errorf("Поля вне патча не должны меняться, получено имя %s", issue.FullName)
+	}
+	if issue.Density == nil || *issue.Density != 250 {
+		t.Errorf("Ожидалась пересчитанная плотность 250, получено %v", issue.Density)
+	}
+
+	history, err := service.GetIssueHistory(created.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения истории: %v", err)
+	}
+	changed := make(map[string]model.AuditEntry)
+	for _, entry := range history {
+		changed[entry.Field] = entry
+	}
+	for _, field := range []string{"contactInfo", "volume", "weight", "density"} {
+		if _, ok := changed[field]; !ok {
+			t.Errorf("Ожидалась запись истории для поля %s, получено %v", field, history)
+		}
+	}
+	if entry := changed["contactInfo"]; entry.OldValue != created.ContactInfo || entry.NewValue != issue.ContactInfo {
+		t.Errorf("Неверная запись истории контакта: %+v", entry)
+	}
+
+	issue, err = service.PatchIssue(created.ID, []byte(`{"volume": null}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if issue.Volume != nil {
+		t.Errorf("null в патче должен удалять значение, получено %v", *issue.Volume)
+	}
+	if issue.Density != nil {
+		t.Errorf("Без объема плотность должна сбрасываться, получено %v", *issue.Density)
+	}
+
+	issue, err = service.PatchIssue(created.ID, []byte(`{"density": 300}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if issue.Density == nil || *issue.Density != 300 {
+		t.Errorf("Без объема должна сохраняться указанная плотность 300, получено %v", issue.Density)
+	}
+}
+
+func TestCreateIssueKeepsDensity(t *testing.T) {
+	service := newTestService(t)
+
+	density := 150.0
+	req := newTestIssueRequest()
+	req.Volume, req.Weight, req.Density = nil, nil, &density
+	issue, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if issue.Density == nil || *issue.Density != density {
+		t.Errorf("Ожидалась указанная плотность %v, получено %v", density, issue.Density)
+	}
+}
+
+func TestPatchIssueValidation(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		field string
+	}{
+		{name: "удаление обязательного поля", patch: `{"fullName": null}`, field: "fullName"},
+		{name: "недопустимый статус", patch: `{"status": "unknown"}`, field: "status"},
+		{name: "неверный тип", patch: `{"volume": "много"}`, field: "volume"},
+		{name: "нередактируемое поле", patch: `{"id": 42}`, field: "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.PatchIssue(created.ID, []byte(tt.patch), 0)
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Ожидалась ошибка валидации, получено %v", err)
+			}
+			if _, ok := validationErr.Fields[tt.field]; !ok {
+				t.Errorf("Ожидалась ошибка для поля %s, получено %v", tt.field, validationErr.Fields)
+			}
+		})
+	}
+
+	if _, err := service.PatchIssue(created.ID, []byte(`["status"]`), 0); err != ErrInvalidPatch {
+		t.Errorf("Ожидалась ошибка ErrInvalidPatch, получено %v", err)
+	}
+}
+
+func TestPatchIssueVersionConflict(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	updated, err := service.PatchIssue(created.ID, []byte(`{"assignee": "@first"}`), created.Version)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if updated.Version != created.Version+1 {
+		t.Errorf("Ожидалась версия %d, получена %d", created.Version+1, updated.Version)
+	}
+
+	// Второй менеджер редактирует устаревшую версию
+	if _, err := service.PatchIssue(created.ID, []byte(`{"assignee": "@second"}`), created.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Ожидалась ошибка ErrVersionConflict, получено %v", err)
+	}
+
+	// Параллельное сохранение двух загруженных копий
+	first, err := service.repo.GetIssueByID(created.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	second, err := service.repo.GetIssueByID(created.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if err := service.repo.UpdateIssue(first); err != nil {
+		t.Fatalf("Ошибка сохранения заявки: %v", err)
+	}
+	if err := service.repo.UpdateIssue(second); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Ожидалась ошибка ErrVersionConflict, получено %v", err)
+	}
+}
+
+func TestIssueActionsVersionConflict(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	other, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Каждое действие увеличивает версию заявки
+	assigned, err := service.AssignIssue(created.ID, &model.AssignIssueRequest{Assignee: "Анна"}, created.Version)
+	if err != nil {
+		t.Fatalf("Ошибка назначения менеджера: %v", err)
+	}
+	tagged, err := service.AddIssueTags(created.ID, []string{"срочно"}, assigned.Version)
+	if err != nil {
+		t.Fatalf("Ошибка добавления тегов: %v", err)
+	}
+	if assigned.Version != created.Version+1 || tagged.Version != created.Version+2 {
+		t.Errorf("Ожидались версии %d и %d, получено %d и %d", created.Version+1, created.Version+2, assigned.Version, tagged.Version)
+	}
+
+	stale := created.Version
+	conflicts := map[string]error{
+		"назначение": func() error {
+			_, err := service.AssignIssue(created.ID, &model.AssignIssueRequest{Assignee: "Олег"}, stale)
+			return err
+		}(),
+		"добавление тега": func() error {
+			_, err := service.AddIssueTags(created.ID, []string{"опт"}, stale)
+			return err
+		}(),
+		"удаление тега": func() error {
+			_, err := service.RemoveIssueTag(created.ID, "срочно", stale)
+			return err
+		}(),
+		"подтверждение инвойса": func() error {
+			_, err := service.ConfirmInvoice(created.ID, nil, stale)
+			return err
+		}(),
+		"объединение": func() error {
+			_, err := service.MergeIssues(created.ID, &model.MergeIssuesRequest{SourceIDs: []uint{other.ID}}, stale)
+			return err
+		}(),
+		"удаление": service.DeleteIssue(created.ID, stale),
+	}
+	for name, err := range conflicts {
+		if !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("%s устаревшей версии: ожидалась ошибка ErrVersionConflict, получено %v", name, err)
+		}
+	}
+
+	if err := service.DeleteIssue(created.ID, tagged.Version); err != nil {
+		t.Errorf("Ошибка удаления заявки: %v", err)
+	}
+}
+
+func TestPatchIssueRollsBack(t *testing.T) {
+	service, db := newTestServiceDB(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Журнал недоступен - изменение не должно сохраниться без записи в нем
+	if err := db.Exec("DROP TABLE audit_entries").Error; err != nil {
+		t.Fatalf("Ошибка подготовки базы: %v", err)
+	}
+	if _, err := service.PatchIssue(created.ID, []byte(`{"fullName": "Петр Петров"}`), created.Version); err == nil {
+		t.Fatalf("Ожидалась ошибка записи журнала")
+	}
+
+	issue, err := service.GetIssueByID(created.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if issue.FullName != created.FullName || issue.Version != created.Version {
+		t.Errorf("Изменение должно откатываться, получено %s (версия %d)", issue.FullName, issue.Version)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"strings"
+
+	"calc_example/internal/marketplace"
+	"calc_example/internal/model"
+)
+
+// Product Service
+
+// parseProductLinks находит в тексте ссылки на товары. Текст без ссылок
+// допустим, а адреса, которые не удалось разобрать, считаются ошибкой
+func parseProductLinks(text string) ([]model.ProductLink, error) {
+	found, invalid := marketplace.Extract(text)
+	if len(invalid) > 0 {
+		result := &ValidationError{}
+		result.add("existingProductLinks", "недопустимые ссылки: "+strings.Join(invalid, ", "))
+		return nil, result
+	}
+	return productLinks(found), nil
+}
+
+func productLinks(found []marketplace.Link) []model.ProductLink {
+	links := make([]model.ProductLink, 0, len(found))
+	for i, link := range found {
+		links = append(links, model.ProductLink{
+			Position:    i + 1,
+			URL:         link.URL,
+			Marketplace: link.Marketplace,
+			ProductID:   link.ProductID,
+		})
+	}
+	return links
+}
+
+// issueProductLinks возвращает ссылки на товары заявки, пустой список вместо nil
+func issueProductLinks(issue *model.Issue) []model.ProductLink {
+	if issue.ProductLinks == nil {
+		return []model.ProductLink{}
+	}
+	return issue.ProductLinks
+}
+
+// LinkProducts сохраняет ссылки на товары для заявок, созданных до
+// появления разбора ссылок. Недопустимые адреса в старых заявках
+// пропускаются. Возвращает количество заявок, в которых найдены ссылки
+func (s *Service) LinkProducts() (int, error) {
+	issues, err := s.repo.GetIssuesWithoutProductLinks()
+	if err != nil {
+		return 0, err
+	}
+
+	linked := 0
+	for _, issue := range issues {
+		found, _ := marketplace.Extract(issue.ExistingProductLinks)
+		if len(found) == 0 {
+			continue
+		}
+		if err := s.repo.SetProductLinks(issue.ID, productLinks(found)); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+// GetProductGroups возвращает товары, на которые ссылаются не меньше
+// minIssues заявок
+func (s *Service) GetProductGroups(minIssues int) ([]model.ProductGroup, error) {
+	if minIssues < 1 {
+		minIssues = 1
+	}
+
+	groups, err := s.repo.GetProductGroups(minIssues)
+	if groups == nil {
+		groups = []model.ProductGroup{}
+	}
+	return groups, err
+}
+
+// GetProductIssues возвращает заявки со ссылкой на товар маркетплейса
+func (s *Service) GetProductIssues(marketplace, productID string) ([]model.IssueResponse, error) {
+	issues, err := s.repo.GetProductIssues(strings.ToLower(marketplace), productID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.IssueResponse, 0, len(issues))
+	for i := range issues {
+		responses = append(responses, *s.toIssueResponse(&issues[i]))
+	}
+	return responses, nil
+}
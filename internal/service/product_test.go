@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"calc_example/internal/marketplace"
+	"calc_example/internal/model"
+)
+
+func TestIssueProductLinks(t *testing.T) {
+	service := newTestService(t)
+
+	req := newTestIssueRequest()
+	req.ExistingProductLinks = "https://detail.1688.com/offer/652345678901.html?spm=a26352\nи ещё https://www.wildberries.ru/catalog/123456789/detail.aspx"
+	first, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if len(first.ProductLinks) != 2 {
+		t.Fatalf("Ожидалось две ссылки на товары, получено %+v", first.ProductLinks)
+	}
+	if link := first.ProductLinks[0]; link.Marketplace != marketplace.Alibaba1688 || link.ProductID != "652345678901" {
+		t.Errorf("Неверная ссылка: %+v", link)
+	}
+
+	req = newTestIssueRequest()
+	req.ContactInfo = "+7-999-765-43-21"
+	req.ExistingProductLinks = "m.1688.com/offer/652345678901.html"
+	second, err := service.CreateIssue(req)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	groups, err := service.GetProductGroups(2)
+	if err != nil {
+		t.Fatalf("Ошибка получения товаров: %v", err)
+	}
+	if len(groups) != 1 || groups[0].ProductID != "652345678901" || groups[0].IssueCount != 2 || groups[0].LastIssueID != second.ID {
+		t.Fatalf("Ожидался один общий товар двух заявок, получено %+v", groups)
+	}
+
+	issues, err := service.GetProductIssues("1688", "652345678901")
+	if err != nil {
+		t.Fatalf("Ошибка получения заявок по товару: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Errorf("Ожидалось две заявки по товару, получено %d", len(issues))
+	}
+
+	// Изменение текста ссылок заменяет сохраненные ссылки
+	patched, err := service.PatchIssue(second.ID, []byte(`{"existingProductLinks": "https://www.ozon.ru/product/lampa-1234567890/"}`), 0)
+	if err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if len(patched.ProductLinks) != 1 || patched.ProductLinks[0].Marketplace != marketplace.Ozon {
+		t.Errorf("Ссылки не обновлены: %+v", patched.ProductLinks)
+	}
+	if groups, _ := service.GetProductGroups(2); len(groups) != 0 {
+		t.Errorf("После изменения общих товаров быть не должно, получено %+v", groups)
+	}
+
+	var validationErr *ValidationError
+	_, err = service.PatchIssue(second.ID, []byte(`{"existingProductLinks": "ftp://1688.com/offer/1.html"}`), 0)
+	if !errors.As(err, &validationErr) || validationErr.Fields["existingProductLinks"] == "" {
+		t.Errorf("Ожидалась ошибка недопустимой ссылки, получено %v", err)
+	}
+}
+
+func TestLinkProducts(t *testing.T) {
+	service := newTestService(t)
+
+	legacy := &model.Issue{
+		FullName:               "Иван Иванов",
+		ContactInfo:            "+79991234567",
+		PreferredContactMethod: model.ContactMethodPhone,
+		ProductDescription:     "Лампы",
+		ExistingProductLinks:   "https://item.taobao.com/item.htm?id=712345678901 ftp://broken",
+		ExpectedDeliveryDate:   "2024-12-01",
+		Status:                 model.StatusOpen,
+		Version:                1,
+	}
+	if err := service.repo.CreateIssue(legacy); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	linked, err := service.LinkProducts()
+	if err != nil || linked != 1 {
+		t.Fatalf("Ожидалась одна заявка с разобранными ссылками, получено %d %v", linked, err)
+	}
+
+	issue, err := service.GetIssueByID(legacy.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if len(issue.ProductLinks) != 1 || issue.ProductLinks[0].ProductID != "712345678901" {
+		t.Errorf("Неверные ссылки на товары: %+v", issue.ProductLinks)
+	}
+
+	if linked, _ := service.LinkProducts(); linked != 0 {
+		t.Errorf("Повторный разбор не должен находить заявки, получено %d", linked)
+	}
+}
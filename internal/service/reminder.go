@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+var ErrReminderNotFound = errors.New("напоминание не найдено")
+
+// Reminder Service
+
+// AssignIssue назначает менеджера заявки. Если version не равна нулю,
+// изменение применяется только к этой версии заявки
+func (s *Service) AssignIssue(id uint, req *model.AssignIssueRequest, version uint) (*model.IssueResponse, error) {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	issue.Assignee = strings.TrimSpace(req.Assignee)
+
+	if err := s.repo.UpdateIssue(issue); err != nil {
+		return nil, err
+	}
+
+	return s.toIssueResponse(issue), nil
+}
+
+func (s *Service) CreateReminder(issueID uint, req *model.CreateReminderRequest) (*model.Reminder, error) {
+	if _, err := s.getIssue(issueID); err != nil {
+		return nil, err
+	}
+
+	reminder := &model.Reminder{
+		IssueID:  issueID,
+		RemindAt: req.RemindAt.UTC(),
+		Note:     strings.TrimSpace(req.Note),
+	}
+
+	if err := s.repo.CreateReminder(reminder); err != nil {
+		return nil, err
+	}
+
+	return reminder, nil
+}
+
+func (s *Service) GetIssueReminders(issueID uint) ([]model.Reminder, error) {
+	if _, err := s.getIssue(issueID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetIssueReminders(issueID)
+}
+
+func (s *Service) DeleteReminder(issueID, id uint) error {
+	deleted, err := s.repo.DeleteReminder(issueID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrReminderNotFound
+	}
+	return nil
+}
+
+func (s *Service) GetDueReminders(now time.Time) ([]model.Reminder, error) {
+	return s.repo.GetDueReminders(now)
+}
+
+func (s *Service) CompleteReminder(id uint) error {
+	return s.repo.CompleteReminder(id, time.Now())
+}
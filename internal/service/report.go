@@ -0,0 +1,23 @@
+package service
+
+import (
+	"math"
+
+	"calc_example/internal/model"
+)
+
+// Report Service
+func (s *Service) GetSourceReport(period model.ReportPeriod) ([]model.SourceReportRow, error) {
+	rows, err := s.repo.GetSourceReport(period)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if rows[i].Leads > 0 {
+			rows[i].ConversionRate = math.Round(float64(rows[i].Conversions)/float64(rows[i].Leads)*1000) / 1000
+		}
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+func TestResolveAttribution(t *testing.T) {
+	tests := []struct {
+		name         string
+		attribution  model.Attribution
+		wantSource   string
+		wantCampaign string
+	}{
+		{
+			name:         "метки из посадочной страницы",
+			attribution:  model.Attribution{LandingPage: "https://example.com/?utm_source=yandex&utm_campaign=spring"},
+			wantSource:   "yandex",
+			wantCampaign: "spring",
+		},
+		{
+			name:        "источник по реферреру",
+			attribution: model.Attribution{Referrer: "https://www.vk.com/wall-1"},
+			wantSource:  "vk.com",
+		},
+		{
+			name:        "явно переданный источник",
+			attribution: model.Attribution{Source: "Авито", UTMSource: "yandex"},
+			wantSource:  "Авито",
+		},
+		{
+			name:       "по умолчанию",
+			wantSource: model.DefaultLeadSource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attribution := tt.attribution
+			resolveAttribution(&attribution)
+			if attribution.Source != tt.wantSource || attribution.UTMCampaign != tt.wantCampaign {
+				t.Errorf("Ожидалось %s/%s, получено %s/%s", tt.wantSource, tt.wantCampaign, attribution.Source, attribution.UTMCampaign)
+			}
+		})
+	}
+}
+
+func TestSourceReport(t *testing.T) {
+	service := newTestService(t)
+
+	for i, source := range []string{"yandex", "yandex", "vk"} {
+		req := newTestIssueRequest()
+		req.Source = source
+		req.UTMCampaign = "spring"
+		issue, err := service.CreateIssue(req)
+		if err != nil {
+			t.Fatalf("Ошибка создания заявки: %v", err)
+		}
+		if i == 0 {
+			if _, err := service.PatchIssue(issue.ID, []byte(`{"status": "closed", "quoteAmount": 1000}`), 0); err != nil {
+				t.Fatalf("Ошибка изменения заявки: %v", err)
+			}
+		}
+	}
+
+	rows, err := service.GetSourceReport(model.ReportPeriod{})
+	if err != nil {
+		t.Fatalf("Ошибка построения отчета: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Ожидалось 2 строки отчета, получено %v", rows)
+	}
+
+	yandex := rows[0]
+	if yandex.Source != "yandex" || yandex.Campaign != "spring" || yandex.Leads != 2 || yandex.Conversions != 1 {
+		t.Errorf("Неверная строка отчета: %+v", yandex)
+	}
+	if yandex.ConversionRate != 0.5 || yandex.Revenue != 1000 {
+		t.Errorf("Ожидались конверсия 0.5 и выручка 1000, получено %+v", yandex)
+	}
+}
+
+func TestSourceReportPeriodWithOffset(t *testing.T) {
+	service := newTestService(t)
+
+	if _, err := service.CreateIssue(newTestIssueRequest()); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Границы с западным смещением текстом меньше UTC, с восточным - больше
+	now := time.Now().UTC()
+	from := now.Add(time.Minute).In(time.FixedZone("EST", -5*60*60))
+	to := now.Add(-time.Minute).In(time.FixedZone("MSK", 3*60*60))
+	for _, period := range []model.ReportPeriod{{From: &from}, {To: &to}} {
+		rows, err := service.GetSourceReport(period)
+		if err != nil {
+			t.Fatalf("Ошибка построения отчета: %v", err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("Заявка вне периода попала в отчет: %+v", rows)
+		}
+	}
+}
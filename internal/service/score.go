@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+// deliveryDateLayouts - форматы, в которых клиенты указывают желаемую дату доставки
+var deliveryDateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+// score оценивает заявку по настроенным правилам и сохраняет в ней оценку
+// вместе со списком сработавших правил
+func (s *Service) score(issue *model.Issue, now time.Time) {
+	factors := s.scoreFactors(issue, now)
+
+	issue.Score = 0
+	for _, factor := range factors {
+		issue.Score += factor.Points
+	}
+
+	data, err := json.Marshal(factors)
+	if err != nil {
+		data = []byte("[]")
+	}
+	issue.ScoreFactors = string(data)
+}
+
+func (s *Service) scoreFactors(issue *model.Issue, now time.Time) []model.ScoreFactor {
+	rules := s.scoring
+	factors := []model.ScoreFactor{}
+	add := func(rule, description string, points int) {
+		if points != 0 {
+			factors = append(factors, model.ScoreFactor{Rule: rule, Description: description, Points: points})
+		}
+	}
+
+	if issue.HasChinaExperience {
+		add("chinaExperience", "Есть опыт работы с Китаем", rules.ChinaExperience)
+	}
+	if issue.HasSupplierContacts {
+		add("supplierContacts", "Есть контакты поставщика", rules.SupplierContacts)
+	}
+	if issue.Volume != nil {
+		add("volume", "Указан объем груза", rules.Volume)
+	}
+	if issue.Weight != nil {
+		add("weight", "Указан вес груза", rules.Weight)
+	}
+	if strings.TrimSpace(issue.PreviousInvoiceFile) != "" {
+		add("previousInvoice", "Приложен инвойс прошлой поставки", rules.PreviousInvoice)
+	}
+
+	if deadline, ok := parseDeliveryDate(issue.ExpectedDeliveryDate); ok {
+		days := int(deadline.Sub(now.Truncate(24*time.Hour)).Hours() / 24)
+		switch {
+		case days < 0:
+			add("deadlinePassed", "Желаемая дата доставки уже прошла", rules.DeadlinePassed)
+		case days <= rules.DeadlineSoonDays:
+			add("deadlineSoon", fmt.Sprintf("Доставка нужна в течение %d дн.", rules.DeadlineSoonDays), rules.DeadlineSoon)
+		}
+	}
+
+	if points, ok := rules.Sources[strings.ToLower(issue.Source)]; ok {
+		add("source", "Источник: "+issue.Source, points)
+	}
+
+	return factors
+}
+
+func parseDeliveryDate(value string) (time.Time, bool) {
+	for _, layout := range deliveryDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// issueScoreFactors восстанавливает список правил, сохраненный в заявке
+func issueScoreFactors(issue *model.Issue) []model.ScoreFactor {
+	factors := []model.ScoreFactor{}
+	if issue.ScoreFactors != "" {
+		_ = json.Unmarshal([]byte(issue.ScoreFactors), &factors)
+	}
+	return factors
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+func TestScoreIssue(t *testing.T) {
+	service := newTestService(t)
+	now := time.Date(2024, 11, 1, 12, 0, 0, 0, time.UTC)
+	volume := 2.5
+
+	tests := []struct {
+		name      string
+		issue     model.Issue
+		wantScore int
+		wantRules []string
+	}{
+		{
+			name:      "пустая заявка",
+			issue:     model.Issue{ExpectedDeliveryDate: "когда-нибудь"},
+			wantScore: 0,
+		},
+		{
+			name: "подготовленный клиент",
+			issue: model.Issue{
+				HasChinaExperience:   true,
+				HasSupplierContacts:  true,
+				Volume:               &volume,
+				PreviousInvoiceFile:  "invoice.pdf",
+				ExpectedDeliveryDate: "01.12.2024",
+			},
+			wantScore: 70,
+			wantRules: []string{"chinaExperience", "supplierContacts", "volume", "previousInvoice", "deadlineSoon"},
+		},
+		{
+			name: "прошедший срок и источник",
+			issue: model.Issue{
+				ExpectedDeliveryDate: "2024-10-01",
+				Attribution:          model.Attribution{Source: "Авито"},
+			},
+			wantScore: -15,
+			wantRules: []string{"deadlinePassed", "source"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := tt.issue
+			service.score(&issue, now)
+
+			if issue.Score != tt.wantScore {
+				t.Errorf("Ожидалась оценка %d, получено %d", tt.wantScore, issue.Score)
+			}
+			factors := issueScoreFactors(&issue)
+			if len(factors) != len(tt.wantRules) {
+				t.Fatalf("Ожидалось правил %d, получено %v", len(tt.wantRules), factors)
+			}
+			for i, rule := range tt.wantRules {
+				if factors[i].Rule != rule {
+					t.Errorf("Ожидалось правило %s, получено %s", rule, factors[i].Rule)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAllIssuesSortedByScore(t *testing.T) {
+	service := newTestService(t)
+
+	strong := newTestIssueRequest()
+	strong.FullName = "Петр Петров"
+	strong.ContactInfo = "+79990000001"
+	strong.HasSupplierContacts = true
+	created, err := service.CreateIssue(strong)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if created.Score <= 0 || len(created.ScoreFactors) == 0 {
+		t.Fatalf("Ожидалась оценка с пояснением, получено %d %v", created.Score, created.ScoreFactors)
+	}
+
+	weak := newTestIssueRequest()
+	weak.HasChinaExperience = false
+	if _, err := service.CreateIssue(weak); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	issues, err := service.GetAllIssues(model.IssueFilter{SortBy: model.IssueSortScore})
+	if err != nil {
+		t.Fatalf("Ошибка получения заявок: %v", err)
+	}
+	if len(issues.Items) != 2 || issues.Items[0].ID != created.ID {
+		t.Errorf("Ожидалась первой заявка %d с наибольшей оценкой", created.ID)
+	}
+}
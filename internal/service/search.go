@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"calc_example/internal/model"
+	"calc_example/internal/search"
+)
+
+// SearchIssues ищет заявки по словам запроса в имени, контактах, описании
+// товара, ссылках и комментариях. Русские слова находятся в любой форме,
+// результаты упорядочены по релевантности
+func (s *Service) SearchIssues(query string, limit int) ([]model.IssueSearchResult, error) {
+	validation := &ValidationError{}
+
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		validation.add("q", "запрос должен содержать слово хотя бы из двух символов")
+	}
+	if limit == 0 {
+		limit = model.DefaultSearchLimit
+	}
+	if limit < 0 || limit > model.MaxSearchLimit {
+		validation.add("limit", fmt.Sprintf("значение должно быть от 1 до %d", model.MaxSearchLimit))
+	}
+	if len(validation.Fields) > 0 {
+		return nil, validation
+	}
+
+	hits, err := s.repo.SearchIssues(search.MatchQuery(terms), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.IssueID
+	}
+	issues, err := s.repo.GetIssuesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Issue, len(issues))
+	for i := range issues {
+		byID[issues[i].ID] = &issues[i]
+	}
+
+	results := make([]model.IssueSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		issue, ok := byID[hit.IssueID]
+		if !ok {
+			continue
+		}
+
+		highlights := make(map[string]string)
+		for i, snippet := range hit.Snippets {
+			if i < len(model.IssueSearchFields) && strings.Contains(snippet, search.HighlightStart) {
+				highlights[model.IssueSearchFields[i]] = snippet
+			}
+		}
+
+		results = append(results, model.IssueSearchResult{
+			IssueResponse: *s.toIssueResponse(issue),
+			Rank:          hit.Rank,
+			Highlights:    highlights,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"calc_example/internal/model"
+)
+
+func TestSearchIssues(t *testing.T) {
+	service := newTestService(t)
+
+	lamps := newTestIssueRequest()
+	lamps.FullName = "Пётр Смирнов"
+	lamps.ContactInfo = "+7 912 345-67-89"
+	lamps.ProductDescription = "Настольные лампы и светильники из Китая"
+	first, err := service.CreateIssue(lamps)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	toys := newTestIssueRequest()
+	toys.FullName = "Анна Лампова"
+	toys.PreferredContactMethod = "email"
+	toys.ContactInfo = "anna@example.com"
+	toys.ProductDescription = "Детские игрушки"
+	second, err := service.CreateIssue(toys)
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+		field string
+	}{
+		{"другая форма слова", "лампа", []uint{second.ID, first.ID}, "productDescription"},
+		{"буква ё", "петр", []uint{first.ID}, "fullName"},
+		{"несколько слов", "светильник китай", []uint{first.ID}, "productDescription"},
+		{"телефон без разделителей", "79123456789", []uint{first.ID}, "contactInfo"},
+		{"почта", "anna@example.com", []uint{second.ID}, "contactInfo"},
+		{"нет совпадений", "мебель", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.SearchIssues(tt.query, 0)
+			if err != nil {
+				t.Fatalf("Ошибка поиска: %v", err)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("Ожидалось %d заявок, найдено %d", len(tt.want), len(results))
+			}
+			for i, result := range results {
+				if result.ID != tt.want[i] {
+					t.Errorf("На позиции %d ожидалась заявка %d, получена %d", i, tt.want[i], result.ID)
+				}
+			}
+			if tt.field != "" {
+				highlight := results[len(results)-1].Highlights[tt.field]
+				if !strings.Contains(highlight, "<mark>") {
+					t.Errorf("Ожидалось выделение в поле %s, получено %v", tt.field, results[len(results)-1].Highlights)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchIssuesIndexUpdates(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	if _, err := service.CreateReminder(created.ID, &model.CreateReminderRequest{
+		RemindAt: time.Now().Add(time.Hour),
+		Note:     "Уточнить размеры коробки",
+	}); err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+	results, err := service.SearchIssues("коробка", 0)
+	if err != nil {
+		t.Fatalf("Ошибка поиска: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Highlights["notes"], "<mark>") {
+		t.Fatalf("Ожидалась заявка с совпадением в комментариях, получено %+v", results)
+	}
+
+	if _, err := service.PatchIssue(created.ID, []byte(`{"productDescription": "Запчасти для велосипедов"}`), 0); err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	if results, _ := service.SearchIssues("велосипед", 0); len(results) != 1 {
+		t.Errorf("Измененная заявка должна находиться по новому описанию")
+	}
+	if results, _ := service.SearchIssues("электронные", 0); len(results) != 0 {
+		t.Errorf("Измененная заявка не должна находиться по старому описанию")
+	}
+
+	if err := service.DeleteIssue(created.ID, 0); err != nil {
+		t.Fatalf("Ошибка удаления заявки: %v", err)
+	}
+	if results, _ := service.SearchIssues("велосипед", 0); len(results) != 0 {
+		t.Errorf("Удаленная заявка не должна находиться")
+	}
+}
+
+func TestSearchIssuesInvalidQuery(t *testing.T) {
+	service := newTestService(t)
+
+	for _, query := range []string{"", "  ", "я", "!!!"} {
+		_, err := service.SearchIssues(query, 0)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields["q"] == "" {
+			t.Errorf("Запрос %q: ожидалась ошибка в поле q, получено %v", query, err)
+		}
+	}
+
+	if _, err := service.SearchIssues("лампа", model.MaxSearchLimit+1); err == nil {
+		t.Errorf("Ожидалась ошибка слишком большого limit")
+	}
+}
@@ -1,149 +1,308 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"calc_example/internal/config"
 	"calc_example/internal/model"
 	"calc_example/internal/repository"
+	"calc_example/internal/sla"
+	"calc_example/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIssueNotFound = errors.New("заявка не найдена")
+	ErrTagNotFound   = errors.New("тег не найден")
+
+	ErrIssueNotInTrash = errors.New("заявка не найдена в корзине")
+	ErrIssueMerged     = errors.New("заявка объединена с другой заявкой и не может быть восстановлена")
+	ErrVersionConflict = repository.ErrVersionConflict
 )
 
 type Service struct {
-	repo *repository.Repository
+	repo      *repository.Repository
+	sla       *sla.Policy
+	duplicate config.DuplicateConfig
+	scoring   config.ScoringConfig
+	files     storage.Storage
+	presign   time.Duration
+	upload    config.UploadConfig
 }
 
-func New(repo *repository.Repository) *Service {
-	return &Service{repo: repo}
+func New(repo *repository.Repository, files storage.Storage, cfg *config.Config) *Service {
+	return &Service{
+		repo:      repo,
+		files:     files,
+		presign:   cfg.Storage.PresignExpiry,
+		upload:    cfg.Upload,
+		sla:       sla.New(cfg.SLA),
+		duplicate: cfg.Duplicate,
+		scoring:   cfg.Scoring,
+	}
 }
 
 // Issue Service
 func (s *Service) CreateIssue(req *model.CreateIssueRequest) (*model.IssueResponse, error) {
-	issue := &model.Issue{
-		FullName:              req.FullName,
-		ContactInfo:           req.ContactInfo,
+	return s.CreateIssueWithFiles(req, nil)
+}
+
+// CreateIssueWithFiles создает заявку вместе с загруженными файлами
+func (s *Service) CreateIssueWithFiles(req *model.CreateIssueRequest, files []model.FileUpload) (*model.IssueResponse, error) {
+	if err := validateIssue(req, &req.PreferredContactMethod, &req.ContactInfo); err != nil {
+		return nil, err
+	}
+
+	uploads, err := s.inspectUploads(files, 0)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := s.storeAttachments(uploads)
+	if err != nil {
+		return nil, err
+	}
+
+	issue := newIssue(req)
+	issue.Attachments = attachments
+	if inv := extractInvoice(uploads, req.PreviousInvoiceFile); inv != nil {
+		applyInvoice(issue, inv)
+	}
+	response, err := s.createIssue(issue)
+	if err != nil {
+		s.removeFiles(attachments)
+		return nil, err
+	}
+	return response, nil
+}
+
+// newIssue создает новую заявку из проверенного запроса
+func newIssue(req *model.CreateIssueRequest) *model.Issue {
+	return &model.Issue{
+		FullName:               req.FullName,
+		ContactInfo:            req.ContactInfo,
 		PreferredContactMethod: req.PreferredContactMethod,
-		HasChinaExperience:    req.HasChinaExperience,
-		HasSupplierContacts:   req.HasSupplierContacts,
-		ProductDescription:    req.ProductDescription,
-		ExistingProductLinks:  req.ExistingProductLinks,
-		Volume:                req.Volume,
-		Weight:                req.Weight,
-		Density:               req.Density,
-		PreviousInvoiceFile:   req.PreviousInvoiceFile,
-		ExpectedDeliveryDate:  req.ExpectedDeliveryDate,
-		Status:                "open",
-	}
-
-	if err := s.repo.CreateIssue(issue); err != nil {
+		HasChinaExperience:     req.HasChinaExperience,
+		HasSupplierContacts:    req.HasSupplierContacts,
+		ProductDescription:     req.ProductDescription,
+		ExistingProductLinks:   req.ExistingProductLinks,
+		Volume:                 req.Volume,
+		Weight:                 req.Weight,
+		Density:                req.Density,
+		PreviousInvoiceFile:    req.PreviousInvoiceFile,
+		ExpectedDeliveryDate:   req.ExpectedDeliveryDate,
+		Status:                 model.StatusOpen,
+		Version:                1,
+		Attribution:            req.Attribution,
+	}
+}
+
+// createIssue дополняет новую заявку производными значениями,
+// связывает ее с клиентом и сохраняет
+func (s *Service) createIssue(issue *model.Issue) (*model.IssueResponse, error) {
+	links, err := parseProductLinks(issue.ExistingProductLinks)
+	if err != nil {
 		return nil, err
 	}
+	issue.ProductLinks = links
 
-	return &model.IssueResponse{
-		ID:                    issue.ID,
-		FullName:              issue.FullName,
-		ContactInfo:           issue.ContactInfo,
-		PreferredContactMethod: issue.PreferredContactMethod,
-		HasChinaExperience:    issue.HasChinaExperience,
-		HasSupplierContacts:   issue.HasSupplierContacts,
-		ProductDescription:    issue.ProductDescription,
-		ExistingProductLinks:  issue.ExistingProductLinks,
-		Volume:                issue.Volume,
-		Weight:                issue.Weight,
-		Density:               issue.Density,
-		PreviousInvoiceFile:   issue.PreviousInvoiceFile,
-		ExpectedDeliveryDate:  issue.ExpectedDeliveryDate,
-		Status:                issue.Status,
-		CreatedAt:             issue.CreatedAt,
-		UpdatedAt:             issue.UpdatedAt,
-	}, nil
+	resolveAttribution(&issue.Attribution)
+	recalculate(issue)
+	s.score(issue, time.Now())
+
+	// Копия заявки повторяет исходную намеренно и дублем не считается
+	if issue.ClonedFromID == nil {
+		duplicateOf, err := s.findDuplicate(issue, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		issue.DuplicateOfID = duplicateOf
+	}
+
+	// Связи с исходной заявкой сохраняются вместе с новой заявкой
+	if issue.ClonedFromID != nil {
+		issue.Links = append(issue.Links, model.IssueLink{LinkedIssueID: *issue.ClonedFromID, Type: model.LinkTypeRepeatOf})
+	}
+	if issue.DuplicateOfID != nil {
+		issue.Links = append(issue.Links, model.IssueLink{LinkedIssueID: *issue.DuplicateOfID, Type: model.LinkTypeDuplicateOf})
+	}
+
+	// Клиент создается или дополняется в одной транзакции с заявкой
+	err = s.repo.CreateIssueWithCustomer(issue, newCustomer(issue), func(customer *model.Customer) {
+		updateCustomer(customer, issue)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toIssueResponse(issue), nil
 }
 
 func (s *Service) GetIssueByID(id uint) (*model.IssueResponse, error) {
-	issue, err := s.repo.GetIssueByID(id)
+	issue, err := s.getIssue(id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.IssueResponse{
-		ID:                    issue.ID,
-		FullName:              issue.FullName,
-		ContactInfo:           issue.ContactInfo,
-		PreferredContactMethod: issue.PreferredContactMethod,
-		HasChinaExperience:    issue.HasChinaExperience,
-		HasSupplierContacts:   issue.HasSupplierContacts,
-		ProductDescription:    issue.ProductDescription,
-		ExistingProductLinks:  issue.ExistingProductLinks,
-		Volume:                issue.Volume,
-		Weight:                issue.Weight,
-		Density:               issue.Density,
-		PreviousInvoiceFile:   issue.PreviousInvoiceFile,
-		ExpectedDeliveryDate:  issue.ExpectedDeliveryDate,
-		Status:                issue.Status,
-		CreatedAt:             issue.CreatedAt,
-		UpdatedAt:             issue.UpdatedAt,
-	}, nil
+	return s.toIssueResponse(issue), nil
 }
 
-func (s *Service) GetAllIssues() ([]model.IssueResponse, error) {
-	issues, err := s.repo.GetAllIssues()
+// GetAllIssues возвращает страницу списка заявок, подходящих под фильтр
+func (s *Service) GetAllIssues(filter model.IssueFilter) (*model.IssuePage, error) {
+	cursor, err := normalizeIssueFilter(&filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// При сортировке по дате создания выбирается лишняя заявка, чтобы
+	// узнать, есть ли следующая страница
+	keyset := filter.SortBy == model.IssueSortCreatedAt && filter.Page == 1
+	query := filter
+	if keyset {
+		query.Limit++
+	}
+
+	issues, total, err := s.repo.GetAllIssues(query, cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []model.IssueResponse
-	for _, issue := range issues {
-		responses = append(responses, model.IssueResponse{
-			ID:                    issue.ID,
-			FullName:              issue.FullName,
-			ContactInfo:           issue.ContactInfo,
-			PreferredContactMethod: issue.PreferredContactMethod,
-			HasChinaExperience:    issue.HasChinaExperience,
-			HasSupplierContacts:   issue.HasSupplierContacts,
-			ProductDescription:    issue.ProductDescription,
-			ExistingProductLinks:  issue.ExistingProductLinks,
-			Volume:                issue.Volume,
-			Weight:                issue.Weight,
-			Density:               issue.Density,
-			PreviousInvoiceFile:   issue.PreviousInvoiceFile,
-			ExpectedDeliveryDate:  issue.ExpectedDeliveryDate,
-			Status:                issue.Status,
-			CreatedAt:             issue.CreatedAt,
-			UpdatedAt:             issue.UpdatedAt,
-		})
-	}
-
-	return responses, nil
+	page := &model.IssuePage{
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}
+
+	if keyset {
+		before := cursor != nil && cursor.Before
+		more := len(issues) > filter.Limit
+		if more && before {
+			issues = issues[1:]
+		} else if more {
+			issues = issues[:filter.Limit]
+		}
+
+		// В направлении выборки страница есть, если нашлась лишняя заявка,
+		// в обратном - если выборка шла от курсора
+		hasNext, hasPrev := more, cursor != nil
+		if before {
+			hasNext, hasPrev = true, more
+		}
+		if len(issues) > 0 {
+			first, last := &issues[0], &issues[len(issues)-1]
+			if hasNext {
+				page.NextCursor = encodeCursor(model.IssueCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+			}
+			if hasPrev {
+				page.PrevCursor = encodeCursor(model.IssueCursor{CreatedAt: first.CreatedAt, ID: first.ID, Before: true})
+			}
+		}
+	}
+
+	page.Items = make([]model.IssueResponse, 0, len(issues))
+	for i := range issues {
+		page.Items = append(page.Items, *s.toIssueResponse(&issues[i]))
+	}
+	return page, nil
 }
 
+// UpdateIssue меняет статус заявки
 func (s *Service) UpdateIssue(id uint, req *model.UpdateIssueRequest) (*model.IssueResponse, error) {
-	issue, err := s.repo.GetIssueByID(id)
+	patch, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	issue.Status = req.Status
+	return s.PatchIssue(id, patch, 0)
+}
+
+// DeleteIssue перемещает заявку в корзину. Если version не равна нулю,
+// удаляется только эта версия заявки
+func (s *Service) DeleteIssue(id, version uint) error {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteIssue(issue); err != nil {
+		return err
+	}
+
+	return s.audit(id, model.AuditActionDeleted)
+}
+
+// getIssue загружает заявку, заменяя ошибку отсутствия записи на ErrIssueNotFound
+func (s *Service) getIssue(id uint) (*model.Issue, error) {
+	issue, err := s.repo.GetIssueByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIssueNotFound
+	}
+	return issue, err
+}
 
-	if err := s.repo.UpdateIssue(issue); err != nil {
+// getIssueVersion загружает заявку для изменения и возвращает
+// ErrVersionConflict, если version не равна нулю и отличается от версии заявки
+func (s *Service) getIssueVersion(id, version uint) (*model.Issue, error) {
+	issue, err := s.getIssue(id)
+	if err != nil {
 		return nil, err
 	}
+	if version != 0 && issue.Version != version {
+		return nil, ErrVersionConflict
+	}
+	return issue, nil
+}
+
+func (s *Service) toIssueResponse(issue *model.Issue) *model.IssueResponse {
+	tags := make([]string, 0, len(issue.Tags))
+	for _, tag := range issue.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	var deletedAt *time.Time
+	if issue.DeletedAt.Valid {
+		deletedAt = &issue.DeletedAt.Time
+	}
 
 	return &model.IssueResponse{
-		ID:                    issue.ID,
-		FullName:              issue.FullName,
-		ContactInfo:           issue.ContactInfo,
+		ID:                     issue.ID,
+		FullName:               issue.FullName,
+		ContactInfo:            issue.ContactInfo,
 		PreferredContactMethod: issue.PreferredContactMethod,
-		HasChinaExperience:    issue.HasChinaExperience,
-		HasSupplierContacts:   issue.HasSupplierContacts,
-		ProductDescription:    issue.ProductDescription,
-		ExistingProductLinks:  issue.ExistingProductLinks,
-		Volume:                issue.Volume,
-		Weight:                issue.Weight,
-		Density:               issue.Density,
-		PreviousInvoiceFile:   issue.PreviousInvoiceFile,
-		ExpectedDeliveryDate:  issue.ExpectedDeliveryDate,
-		Status:                issue.Status,
-		CreatedAt:             issue.CreatedAt,
-		UpdatedAt:             issue.UpdatedAt,
-	}, nil
+		HasChinaExperience:     issue.HasChinaExperience,
+		HasSupplierContacts:    issue.HasSupplierContacts,
+		ProductDescription:     issue.ProductDescription,
+		ExistingProductLinks:   issue.ExistingProductLinks,
+		Volume:                 issue.Volume,
+		Weight:                 issue.Weight,
+		Density:                issue.Density,
+		PreviousInvoiceFile:    issue.PreviousInvoiceFile,
+		ExpectedDeliveryDate:   issue.ExpectedDeliveryDate,
+		Status:                 issue.Status,
+		Assignee:               issue.Assignee,
+		SLA:                    s.sla.Evaluate(issue, time.Now()),
+		Tags:                   tags,
+		Links:                  issueLinks(issue),
+		Attachments:            attachmentViews(issue.Attachments),
+		LineItems:              lineItems(issue.LineItems),
+		ProductLinks:           issueProductLinks(issue),
+		Version:                issue.Version,
+		DuplicateOfID:          issue.DuplicateOfID,
+		MergedIntoID:           issue.MergedIntoID,
+		ClonedFromID:           issue.ClonedFromID,
+		CustomerID:             issue.CustomerID,
+		QuoteAmount:            issue.QuoteAmount,
+		LossReason:             issue.LossReason,
+		LossNote:               issue.LossNote,
+		LostAt:                 issue.LostAt,
+		Score:                  issue.Score,
+		ScoreFactors:           issueScoreFactors(issue),
+		DeclaredInvoice:        issue.DeclaredInvoice,
+		Attribution:            issue.Attribution,
+		CreatedAt:              issue.CreatedAt,
+		UpdatedAt:              issue.UpdatedAt,
+		DeletedAt:              deletedAt,
+	}
 }
-
-func (s *Service) DeleteIssue(id uint) error {
-	return s.repo.DeleteIssue(id)
-} 
\ No newline at end of file
@@ -2,32 +2,117 @@ package service
 
 import (
 	"testing"
+	"time"
 
+	"calc_example/internal/config"
 	"calc_example/internal/model"
 	"calc_example/internal/repository"
+	"calc_example/pkg/database"
+	"calc_example/pkg/storage"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
-func TestCreateIssue(t *testing.T) {
-	// Создаем мок репозитория
-	repo := &repository.Repository{}
-	service := New(repo)
+// newTestService создает сервис поверх SQLite в памяти
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	service, _ := newTestServiceDB(t)
+	return service
+}
 
-	// Тестовые данные
-	req := &model.CreateIssueRequest{
-		FullName:              "Иван Иванов",
-		ContactInfo:           "+7-999-123-45-67",
+// newTestServiceDB создает сервис и возвращает его базу для подготовки
+// данных в обход сервиса
+func newTestServiceDB(t *testing.T) (*Service, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: database.Now,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+
+	// Каждое соединение к :memory: открывает отдельную базу
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("Ошибка миграции: %v", err)
+	}
+
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Ошибка создания хранилища: %v", err)
+	}
+
+	return New(repository.New(&database.Database{DB: db}), files, newTestConfig()), db
+}
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		SLA: config.SLAConfig{
+			FirstContactTarget: 15 * time.Minute,
+			QuoteTarget:        8 * time.Hour,
+			WarnBefore:         5 * time.Minute,
+			WorkdayStart:       9 * time.Hour,
+			WorkdayEnd:         18 * time.Hour,
+			WorkDays:           []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Location:           time.UTC,
+		},
+		Duplicate: config.DuplicateConfig{
+			Window:     72 * time.Hour,
+			Similarity: 0.85,
+			Candidates: 200,
+		},
+		Scoring: config.ScoringConfig{
+			ChinaExperience:  15,
+			SupplierContacts: 20,
+			Volume:           10,
+			Weight:           10,
+			PreviousInvoice:  15,
+			DeadlineSoon:     10,
+			DeadlineSoonDays: 60,
+			DeadlinePassed:   -10,
+			Sources:          map[string]int{"авито": -5},
+		},
+		Upload: config.UploadConfig{
+			MaxFileSize:  1 << 20,
+			MaxIssueSize: 2 << 20,
+			AllowedTypes: []string{"application/pdf", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "text/csv", "image/png"},
+		},
+	}
+}
+
+func newTestIssueRequest() *model.CreateIssueRequest {
+	return &model.CreateIssueRequest{
+		FullName:               "Иван Иванов",
+		ContactInfo:            "+7-999-123-45-67",
 		PreferredContactMethod: "Телефон",
-		HasChinaExperience:    true,
-		HasSupplierContacts:   false,
-		ProductDescription:    "Электронные компоненты",
-		ExistingProductLinks:  "https://ozon.ru/product1",
-		ExpectedDeliveryDate:  "2024-12-01",
+		HasChinaExperience:     true,
+		HasSupplierContacts:    false,
+		ProductDescription:     "Электронные компоненты",
+		ExistingProductLinks:   "https://ozon.ru/product1",
+		ExpectedDeliveryDate:   "2024-12-01",
 	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	service := newTestService(t)
+
+	// Тестовые данные
+	req := newTestIssueRequest()
 
 	// Тестируем создание заявки
 	issue, err := service.CreateIssue(req)
 	if err != nil {
-		t.Errorf("Ошибка создания заявки: %v", err)
+		t.Fatalf("Ошибка создания заявки: %v", err)
 	}
 
 	if issue.FullName != req.FullName {
@@ -40,9 +125,11 @@ func TestCreateIssue(t *testing.T) {
 }
 
 func TestUpdateIssue(t *testing.T) {
-	// Создаем мок репозитория
-	repo := &repository.Repository{}
-	service := New(repo)
+	service := newTestService(t)
+
+	if _, err := service.CreateIssue(newTestIssueRequest()); err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
 
 	// Тестовые данные для обновления
 	req := &model.UpdateIssueRequest{
@@ -52,10 +139,278 @@ func TestUpdateIssue(t *testing.T) {
 	// Тестируем обновление заявки
 	issue, err := service.UpdateIssue(1, req)
 	if err != nil {
-		t.Errorf("Ошибка обновления заявки: %v", err)
+		t.Fatalf("Ошибка обновления заявки: %v", err)
 	}
 
 	if issue.Status != req.Status {
 		t.Errorf("Ожидался статус %s, получен %s", req.Status, issue.Status)
 	}
-} 
\ No newline at end of file
+}
+
+func TestIssueTags(t *testing.T) {
+	service := newTestService(t)
+
+	first, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	second, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	if _, err := service.AddIssueTags(first.ID, []string{"Срочно", " электроника "}, 0); err != nil {
+		t.Fatalf("Ошибка добавления тегов: %v", err)
+	}
+	issue, err := service.AddIssueTags(second.ID, []string{"срочно"}, 0)
+	if err != nil {
+		t.Fatalf("Ошибка добавления тегов: %v", err)
+	}
+	if len(issue.Tags) != 1 || issue.Tags[0] != "срочно" {
+		t.Errorf("Ожидался тег 'срочно', получены %v", issue.Tags)
+	}
+
+	anyTagged, err := service.GetAllIssues(model.IssueFilter{Tags: []string{"срочно", "электроника"}})
+	if err != nil {
+		t.Fatalf("Ошибка получения заявок: %v", err)
+	}
+	if len(anyTagged.Items) != 2 {
+		t.Errorf("Ожидалось 2 заявки с любым из тегов, получено %d", len(anyTagged.Items))
+	}
+
+	all, err := service.GetAllIssues(model.IssueFilter{Tags: []string{"срочно", "электроника"}, MatchAllTags: true})
+	if err != nil {
+		t.Fatalf("Ошибка получения заявок: %v", err)
+	}
+	if len(all.Items) != 1 || all.Items[0].ID != first.ID {
+		t.Errorf("Ожидалась только заявка %d со всеми тегами, получено %v", first.ID, all.Items)
+	}
+
+	issue, err = service.RemoveIssueTag(first.ID, "Срочно", 0)
+	if err != nil {
+		t.Fatalf("Ошибка удаления тега: %v", err)
+	}
+	if len(issue.Tags) != 1 || issue.Tags[0] != "электроника" {
+		t.Errorf("Ожидался только тег 'электроника', получены %v", issue.Tags)
+	}
+
+	if _, err := service.RemoveIssueTag(first.ID, "несуществующий", 0); err != ErrTagNotFound {
+		t.Errorf("Ожидалась ошибка ErrTagNotFound, получена %v", err)
+	}
+
+	// Тег существует, но у заявки его уже нет
+	if _, err := service.RemoveIssueTag(first.ID, "срочно", 0); err != ErrTagNotFound {
+		t.Errorf("Ожидалась ошибка ErrTagNotFound для снятого тега, получена %v", err)
+	}
+	unchanged, err := service.GetIssueByID(first.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if unchanged.Version != issue.Version {
+		t.Errorf("Версия заявки не должна меняться, получено %d вместо %d", unchanged.Version, issue.Version)
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	service := newTestService(t)
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	now := time.Now()
+	due, err := service.CreateReminder(issue.ID, &model.CreateReminderRequest{RemindAt: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+	if _, err := service.CreateReminder(issue.ID, &model.CreateReminderRequest{RemindAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+
+	reminders, err := service.GetDueReminders(now)
+	if err != nil {
+		t.Fatalf("Ошибка получения напоминаний: %v", err)
+	}
+	if len(reminders) != 1 || reminders[0].ID != due.ID {
+		t.Fatalf("Ожидалось одно наступившее напоминание %d, получено %v", due.ID, reminders)
+	}
+
+	if err := service.CompleteReminder(due.ID); err != nil {
+		t.Fatalf("Ошибка завершения напоминания: %v", err)
+	}
+
+	reminders, err = service.GetDueReminders(now)
+	if err != nil {
+		t.Fatalf("Ошибка получения напоминаний: %v", err)
+	}
+	if len(reminders) != 0 {
+		t.Errorf("Выполненное напоминание не должно отправляться повторно, получено %v", reminders)
+	}
+}
+
+func TestDueRemindersWithOffset(t *testing.T) {
+	service := newTestService(t)
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Время с восточным смещением текстом больше UTC, с западным - меньше
+	now := time.Now().UTC()
+	due, err := service.CreateReminder(issue.ID, &model.CreateReminderRequest{
+		RemindAt: now.Add(-time.Minute).In(time.FixedZone("MSK", 3*60*60)),
+	})
+	if err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+	if _, err := service.CreateReminder(issue.ID, &model.CreateReminderRequest{
+		RemindAt: now.Add(time.Minute).In(time.FixedZone("EST", -5*60*60)),
+	}); err != nil {
+		t.Fatalf("Ошибка создания напоминания: %v", err)
+	}
+
+	reminders, err := service.GetDueReminders(now.In(time.FixedZone("MSK", 3*60*60)))
+	if err != nil {
+		t.Fatalf("Ошибка получения напоминаний: %v", err)
+	}
+	if len(reminders) != 1 || reminders[0].ID != due.ID {
+		t.Errorf("Ожидалось одно наступившее напоминание %d, получено %v", due.ID, reminders)
+	}
+}
+
+func TestUpdateIssueTracksSLA(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if created.SLA.FirstContact.CompletedAt != nil {
+		t.Errorf("Первый контакт не должен быть зафиксирован у новой заявки")
+	}
+
+	issue, err := service.UpdateIssue(created.ID, &model.UpdateIssueRequest{Status: model.StatusQuoted})
+	if err != nil {
+		t.Fatalf("Ошибка обновления заявки: %v", err)
+	}
+	if issue.SLA.FirstContact.CompletedAt == nil || issue.SLA.Quote.CompletedAt == nil {
+		t.Errorf("Ожидалась фиксация первого контакта и расчета, получено %+v", issue.SLA)
+	}
+}
+
+func TestCloseIssueWithoutContact(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	issue, err := service.UpdateIssue(created.ID, &model.UpdateIssueRequest{Status: model.StatusClosed})
+	if err != nil {
+		t.Fatalf("Ошибка обновления заявки: %v", err)
+	}
+	if issue.SLA.FirstContact.CompletedAt != nil || issue.SLA.FirstContact.Status != model.SLAStatusCancelled {
+		t.Errorf("Закрытие без контакта не должно выполнять показатель, получено %+v", issue.SLA.FirstContact)
+	}
+}
+
+func TestIssueTrash(t *testing.T) {
+	service := newTestService(t)
+
+	issue, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	if err := service.DeleteIssue(issue.ID, 0); err != nil {
+		t.Fatalf("Ошибка удаления заявки: %v", err)
+	}
+	if _, err := service.GetIssueByID(issue.ID); err != ErrIssueNotFound {
+		t.Errorf("Удаленная заявка не должна быть доступна, получена ошибка %v", err)
+	}
+
+	trash, err := service.GetDeletedIssues()
+	if err != nil {
+		t.Fatalf("Ошибка получения корзины: %v", err)
+	}
+	if len(trash) != 1 || trash[0].DeletedAt == nil {
+		t.Fatalf("Ожидалась одна заявка в корзине, получено %v", trash)
+	}
+
+	if _, err := service.RestoreIssue(issue.ID); err != nil {
+		t.Fatalf("Ошибка восстановления заявки: %v", err)
+	}
+	if err := service.PurgeIssue(issue.ID); err != ErrIssueNotInTrash {
+		t.Errorf("Окончательно удалить можно только заявку из корзины, получена ошибка %v", err)
+	}
+
+	if err := service.DeleteIssue(issue.ID, 0); err != nil {
+		t.Fatalf("Ошибка удаления заявки: %v", err)
+	}
+	if err := service.PurgeIssue(issue.ID); err != nil {
+		t.Fatalf("Ошибка окончательного удаления заявки: %v", err)
+	}
+	if _, err := service.RestoreIssue(issue.ID); err != ErrIssueNotInTrash {
+		t.Errorf("Окончательно удаленную заявку нельзя восстановить, получена ошибка %v", err)
+	}
+
+	history, err := service.GetIssueHistory(issue.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения истории: %v", err)
+	}
+	want := []string{model.AuditActionDeleted, model.AuditActionRestored, model.AuditActionDeleted, model.AuditActionPurged}
+	if len(history) != len(want) {
+		t.Fatalf("Ожидалось %d записей в истории, получено %v", len(want), history)
+	}
+	for i, action := range want {
+		if history[i].Action != action {
+			t.Errorf("Запись %d: ожидалось действие %s, получено %s", i, action, history[i].Action)
+		}
+	}
+}
+
+func TestIssueTrashReferences(t *testing.T) {
+	service := newTestService(t)
+
+	original, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	duplicate, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if duplicate.DuplicateOfID == nil {
+		t.Fatalf("Ожидался повтор заявки %d", original.ID)
+	}
+
+	// Повтор не ссылается на окончательно удаленную заявку
+	if err := service.DeleteIssue(original.ID, 0); err != nil {
+		t.Fatalf("Ошибка удаления заявки: %v", err)
+	}
+	if err := service.PurgeIssue(original.ID); err != nil {
+		t.Fatalf("Ошибка окончательного удаления заявки: %v", err)
+	}
+	issue, err := service.GetIssueByID(duplicate.ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if issue.DuplicateOfID != nil || len(issue.Links) != 0 {
+		t.Errorf("Ссылки на удаленную заявку должны очищаться, получено %v %+v", issue.DuplicateOfID, issue.Links)
+	}
+
+	// Источник объединения не восстанавливается
+	source, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if _, err := service.MergeIssues(duplicate.ID, &model.MergeIssuesRequest{SourceIDs: []uint{source.ID}}, 0); err != nil {
+		t.Fatalf("Ошибка объединения заявок: %v", err)
+	}
+	if _, err := service.RestoreIssue(source.ID); err != ErrIssueMerged {
+		t.Errorf("Ожидалась ошибка ErrIssueMerged, получено %v", err)
+	}
+}
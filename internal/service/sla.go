@@ -0,0 +1,61 @@
+package service
+
+import (
+	"time"
+
+	"calc_example/internal/model"
+)
+
+// SLAEscalation - показатель SLA заявки, по которому нужно предупредить менеджеров
+type SLAEscalation struct {
+	Issue  *model.IssueResponse
+	Metric string
+}
+
+// GetSLAEscalations возвращает показатели SLA, которые вот-вот будут нарушены
+func (s *Service) GetSLAEscalations(now time.Time) ([]SLAEscalation, error) {
+	issues, err := s.repo.GetSLAPendingIssues()
+	if err != nil {
+		return nil, err
+	}
+
+	var escalations []SLAEscalation
+	for i := range issues {
+		for _, metric := range s.sla.NeedsEscalation(&issues[i], now) {
+			escalations = append(escalations, SLAEscalation{
+				Issue:  s.toIssueResponse(&issues[i]),
+				Metric: metric,
+			})
+		}
+	}
+
+	return escalations, nil
+}
+
+func (s *Service) MarkSLAEscalated(id uint, metric string) error {
+	return s.repo.MarkSLAEscalated(id, metric)
+}
+
+// applyStatus меняет статус заявки и фиксирует моменты первого контакта
+// и отправки расчета для учета SLA
+func applyStatus(issue *model.Issue, status string, now time.Time) {
+	issue.Status = status
+
+	// Закрытие без контакта первым контактом не считается
+	contacted := status == model.StatusContacted || status == model.StatusQuoted
+	if contacted && issue.FirstContactAt == nil {
+		issue.FirstContactAt = &now
+	}
+	if status == model.StatusQuoted && issue.QuotedAt == nil {
+		issue.QuotedAt = &now
+	}
+
+	if status != model.StatusLost {
+		issue.LossReason = ""
+		issue.LossNote = ""
+		issue.LostAt = nil
+	} else if issue.LostAt == nil {
+		lostAt := now.UTC()
+		issue.LostAt = &lostAt
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Tag Service
+func (s *Service) GetAllTags() ([]model.Tag, error) {
+	return s.repo.GetAllTags()
+}
+
+// AddIssueTags добавляет заявке теги, создавая новые. Если version
+// не равна нулю, изменение применяется только к этой версии заявки
+func (s *Service) AddIssueTags(id uint, names []string, version uint) (*model.IssueResponse, error) {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.FindOrCreateTags(normalizeTags(names))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddIssueTags(issue, tags); err != nil {
+		return nil, err
+	}
+
+	return s.GetIssueByID(id)
+}
+
+// RemoveIssueTag снимает тег с заявки. Если version не равна нулю,
+// изменение применяется только к этой версии заявки
+func (s *Service) RemoveIssueTag(id uint, name string, version uint) (*model.IssueResponse, error) {
+	issue, err := s.getIssueVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.repo.GetTagByName(normalizeTag(name))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Тег, которого нет у заявки, тоже не найден
+	err = s.repo.RemoveIssueTag(issue, tag)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetIssueByID(id)
+}
+
+// normalizeTag приводит тег к нижнему регистру и схлопывает пробелы,
+// чтобы "Срочно" и " срочно " считались одним тегом
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func normalizeTags(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		tag := normalizeTag(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
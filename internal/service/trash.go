@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+
+	"calc_example/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Trash Service
+func (s *Service) GetDeletedIssues() ([]model.IssueResponse, error) {
+	issues, err := s.repo.GetDeletedIssues()
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []model.IssueResponse
+	for i := range issues {
+		responses = append(responses, *s.toIssueResponse(&issues[i]))
+	}
+
+	return responses, nil
+}
+
+// RestoreIssue возвращает заявку из корзины. Источник объединения не
+// восстанавливается: его данные уже перенесены в итоговую заявку
+func (s *Service) RestoreIssue(id uint) (*model.IssueResponse, error) {
+	issue, err := s.getDeletedIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	if issue.MergedIntoID != nil {
+		return nil, ErrIssueMerged
+	}
+
+	if err := s.repo.RestoreIssue(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(id, model.AuditActionRestored); err != nil {
+		return nil, err
+	}
+
+	return s.GetIssueByID(id)
+}
+
+// PurgeIssue окончательно удаляет заявку из корзины
+func (s *Service) PurgeIssue(id uint) error {
+	issue, err := s.getDeletedIssue(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.PurgeIssue(issue); err != nil {
+		return err
+	}
+	s.removeFiles(issue.Attachments)
+
+	return s.audit(id, model.AuditActionPurged)
+}
+
+func (s *Service) getDeletedIssue(id uint) (*model.Issue, error) {
+	issue, err := s.repo.GetDeletedIssueByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIssueNotInTrash
+	}
+	return issue, err
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"calc_example/internal/model"
+	"calc_example/pkg/imaging"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// extensionAliases - равнозначные расширения файлов
+var extensionAliases = map[string]string{
+	".jpeg": ".jpg",
+	".tif":  ".tiff",
+}
+
+// pdfActiveContent - признаки PDF со сценариями и встроенными файлами
+var pdfActiveContent = [][]byte{
+	[]byte("/JavaScript"),
+	[]byte("/JS"),
+	[]byte("/Launch"),
+	[]byte("/EmbeddedFile"),
+}
+
+// maxUnpackRatio - во сколько раз содержимое архива может превышать
+// допустимый размер файла, прежде чем архив будет отклонен
+const maxUnpackRatio = 10
+
+// inspectedUpload - проверенный файл с типом, определенным по содержимому
+type inspectedUpload struct {
+	model.FileUpload
+	MimeType         string
+	QuarantineReason string
+	// Превью и размеры изображения
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+// MaxUploadSize возвращает наибольший объем файлов, который можно приложить
+// к одной заявке
+func (s *Service) MaxUploadSize() int64 {
+	return s.upload.MaxIssueSize
+}
+
+// inspectUploads проверяет размер и тип загружаемых файлов. Недопустимые
+// файлы отклоняются с ошибкой по каждому файлу, подозрительные помечаются
+// для карантина. stored - объем уже приложенных к заявке файлов
+func (s *Service) inspectUploads(files []model.FileUpload, stored int64) ([]inspectedUpload, error) {
+	result := &ValidationError{}
+	inspected := make([]inspectedUpload, 0, len(files))
+	total := stored
+
+	for _, file := range files {
+		name := sanitizeFileName(file.FileName)
+		size := int64(len(file.Data))
+		total += size
+
+		if size > s.upload.MaxFileSize {
+			result.add(name, fmt.Sprintf("размер файла превышает %d МБ", s.upload.MaxFileSize>>20))
+			continue
+		}
+
+		mtype := detectType(name, file.Data)
+		if !isAllowedType(mtype, s.upload.AllowedTypes) {
+			result.add(name, "недопустимый тип файла: "+mtype.String())
+			continue
+		}
+
+		upload := inspectedUpload{FileUpload: file, MimeType: mtype.String()}
+		upload.FileName = name
+
+		if isZip(mtype) {
+			reason, err := s.inspectArchive(file.Data)
+			if err != nil {
+				result.add(name, err.Error())
+				continue
+			}
+			upload.QuarantineReason = reason
+		}
+		if mtype.Is("application/pdf") && hasActiveContent(file.Data) {
+			upload.QuarantineReason = "PDF содержит сценарии или встроенные файлы"
+		}
+		if upload.QuarantineReason == "" && !extensionMatches(name, mtype) {
+			upload.QuarantineReason = "расширение файла не соответствует содержимому (" + mtype.String() + ")"
+		}
+		if err := s.processImage(&upload); err != nil {
+			result.add(name, "не удалось прочитать изображение")
+			continue
+		}
+
+		inspected = append(inspected, upload)
+	}
+
+	if total > s.upload.MaxIssueSize {
+		result.add("files", fmt.Sprintf("общий размер файлов заявки превышает %d МБ", s.upload.MaxIssueSize>>20))
+	}
+	if len(result.Fields) > 0 {
+		return nil, result
+	}
+	return inspected, nil
+}
+
+// processImage удаляет из изображения метаданные и строит превью.
+// Форматы, которые не удается обработать (например, HEIC), сохраняются
+// как есть и без превью
+func (s *Service) processImage(upload *inspectedUpload) error {
+	if !strings.HasPrefix(upload.MimeType, "image/") {
+		return nil
+	}
+
+	img, err := imaging.Process(upload.Data, upload.MimeType, s.upload.ThumbnailSize)
+	if errors.Is(err, imaging.ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	upload.Data = img.Data
+	upload.Thumbnail = img.Thumbnail
+	upload.Width = img.Width
+	upload.Height = img.Height
+	return nil
+}
+
+// inspectArchive проверяет файлы на основе zip (в том числе XLSX): архив
+// с путями за пределы каталога или со слишком большим содержимым
+// отклоняется, архив с макросами отправляется в карантин
+func (s *Service) inspectArchive(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if errors.Is(err, zip.ErrInsecurePath) {
+		return "", fmt.Errorf("архив содержит недопустимые пути")
+	}
+	if err != nil {
+		return "", fmt.Errorf("поврежденный архив")
+	}
+
+	var unpacked uint64
+	reason := ""
+	for _, file := range archive.File {
+		if !isSafeArchivePath(file.Name) {
+			return "", fmt.Errorf("архив содержит недопустимый путь: %s", file.Name)
+		}
+
+		unpacked += file.UncompressedSize64
+		if unpacked > uint64(s.upload.MaxFileSize)*maxUnpackRatio {
+			return "", fmt.Errorf("слишком большой объем архива после распаковки")
+		}
+
+		if strings.EqualFold(path.Base(file.Name), "vbaProject.bin") {
+			reason = "файл содержит макросы"
+		}
+	}
+	return reason, nil
+}
+
+// isSafeArchivePath проверяет, что путь в архиве не выходит за пределы
+// каталога распаковки
+func isSafeArchivePath(name string) bool {
+	if name == "" || strings.Contains(name, `\`) || strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// detectType определяет тип файла по содержимому. CSV с разделителем,
+// отличным от запятой, по содержимому не отличить от текста, поэтому
+// текстовый файл с расширением .csv считается CSV
+func detectType(name string, data []byte) *mimetype.MIME {
+	mtype := mimetype.Detect(data)
+	if mtype.Is("text/plain") && strings.EqualFold(filepath.Ext(name), ".csv") {
+		if csv := mimetype.Lookup("text/csv"); csv != nil {
+			return csv
+		}
+	}
+	return mtype
+}
+
+func isAllowedType(mtype *mimetype.MIME, allowed []string) bool {
+	for _, t := range allowed {
+		if mtype.Is(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func isZip(mtype *mimetype.MIME) bool {
+	for m := mtype; m != nil; m = m.Parent() {
+		if m.Is("application/zip") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActiveContent(data []byte) bool {
+	for _, marker := range pdfActiveContent {
+		if bytes.Contains(data, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// extensionMatches проверяет, что расширение в имени файла соответствует
+// его содержимому. Файлы без расширения проверку проходят
+func extensionMatches(name string, mtype *mimetype.MIME) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == "" {
+		return true
+	}
+	if alias, ok := extensionAliases[ext]; ok {
+		ext = alias
+	}
+	return ext == mtype.Extension()
+}
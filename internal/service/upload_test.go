@@ -0,0 +1,167 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"calc_example/internal/model"
+)
+
+// testPNG - изображение PNG 2x2 без метаданных
+var testPNG = newTestImage("image/png")
+
+// newTestImage кодирует изображение 2x2 в PNG или JPEG
+func newTestImage(mimeType string) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 200, A: 255}), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if mimeType == "image/jpeg" {
+		_ = jpeg.Encode(&buf, img, nil)
+	} else {
+		_ = png.Encode(&buf, img)
+	}
+	return buf.Bytes()
+}
+
+// newTestXLSX собирает zip-архив с файлами XLSX и дополнительными записями
+func newTestXLSX(t *testing.T, extra ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range append([]string{"[Content_Types].xml", "xl/workbook.xml"}, extra...) {
+		if _, err := archive.Create(name); err != nil {
+			t.Fatalf("Ошибка создания архива: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Ошибка создания архива: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestInspectUploads(t *testing.T) {
+	service := newTestService(t)
+
+	tests := []struct {
+		name           string
+		file           model.FileUpload
+		wantError      string
+		wantQuarantine string
+	}{
+		{
+			name: "допустимый PDF",
+			file: model.FileUpload{FileName: "invoice.pdf", Data: []byte("%PDF-1.4 test")},
+		},
+		{
+			name: "допустимый XLSX",
+			file: model.FileUpload{FileName: "invoice.xlsx", Data: newTestXLSX(t)},
+		},
+		{
+			name:      "недопустимый тип",
+			file:      model.FileUpload{FileName: "script.sh", Data: []byte("#!/bin/sh\nrm -rf /\n")},
+			wantError: "недопустимый тип файла",
+		},
+		{
+			name:      "слишком большой файл",
+			file:      model.FileUpload{FileName: "big.png", Data: append(append([]byte{}, testPNG...), make([]byte, 2<<20)...)},
+			wantError: "размер файла превышает",
+		},
+		{
+			name:      "выход за пределы каталога в архиве",
+			file:      model.FileUpload{FileName: "invoice.xlsx", Data: newTestXLSX(t, "../../etc/passwd")},
+			wantError: "архив содержит недопустим",
+		},
+		{
+			name:           "макросы",
+			file:           model.FileUpload{FileName: "invoice.xlsx", Data: newTestXLSX(t, "xl/vbaProject.bin")},
+			wantQuarantine: "макросы",
+		},
+		{
+			name:           "сценарий в PDF",
+			file:           model.FileUpload{FileName: "invoice.pdf", Data: []byte("%PDF-1.4 /OpenAction << /S /JavaScript >>")},
+			wantQuarantine: "сценарии",
+		},
+		{
+			name:           "расширение не соответствует содержимому",
+			file:           model.FileUpload{FileName: "photo.jpg", Data: []byte("%PDF-1.4 test")},
+			wantQuarantine: "расширение",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads, err := service.inspectUploads([]model.FileUpload{tt.file}, 0)
+
+			if tt.wantError != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || !strings.Contains(validationErr.Fields[tt.file.FileName], tt.wantError) {
+					t.Errorf("Ожидалась ошибка %q, получено %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Файл должен быть принят, получено %v", err)
+			}
+			if reason := uploads[0].QuarantineReason; !strings.Contains(reason, tt.wantQuarantine) || (tt.wantQuarantine == "") != (reason == "") {
+				t.Errorf("Ожидался карантин %q, получено %q", tt.wantQuarantine, reason)
+			}
+		})
+	}
+}
+
+func TestInspectUploadsIssueLimit(t *testing.T) {
+	service := newTestService(t)
+
+	file := model.FileUpload{FileName: "photo.png", Data: append(append([]byte{}, testPNG...), make([]byte, 512<<10)...)}
+	_, err := service.inspectUploads([]model.FileUpload{file, file}, 1<<20+1)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields["files"] == "" {
+		t.Errorf("Ожидалась ошибка общего размера файлов, получено %v", err)
+	}
+}
+
+func TestQuarantinedAttachment(t *testing.T) {
+	service := newTestService(t)
+
+	created, err := service.CreateIssue(newTestIssueRequest())
+	if err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	added, err := service.AddAttachments(created.ID, []model.FileUpload{{FileName: "photo.jpg", Data: []byte("%PDF-1.4 test")}}, "")
+	if err != nil {
+		t.Fatalf("Ошибка добавления вложения: %v", err)
+	}
+	if !added[0].Quarantined || added[0].MimeType != "application/pdf" {
+		t.Fatalf("Ожидалось вложение в карантине с определенным типом, получено %+v", added[0])
+	}
+
+	if _, err := service.OpenAttachment(added[0].ID); !errors.Is(err, ErrAttachmentQuarantined) {
+		t.Errorf("Вложение в карантине не должно отдаваться, получено %v", err)
+	}
+
+	released, err := service.ReleaseAttachment(added[0].ID)
+	if err != nil {
+		t.Fatalf("Ошибка снятия с карантина: %v", err)
+	}
+	if released.Quarantined {
+		t.Errorf("Ожидалось снятие с карантина")
+	}
+
+	download, err := service.OpenAttachment(added[0].ID)
+	if err != nil {
+		t.Fatalf("Ошибка получения вложения: %v", err)
+	}
+	download.Content.Close()
+}
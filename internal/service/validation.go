@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ValidationError - ошибка проверки данных заявки с описанием по полям
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %s", name, e.Fields[name]))
+	}
+	return "неверные данные заявки: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	e.Fields[field] = message
+}
+
+// validate проверяет структуру по тегам binding так же, как gin при разборе запроса
+func validate(obj interface{}) error {
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	result := &ValidationError{}
+	for _, fieldError := range fieldErrors {
+		result.add(jsonFieldPath(obj, fieldError.StructNamespace()), validationMessage(fieldError))
+	}
+	return result
+}
+
+// validateIssue проверяет данные заявки по тегам binding, а затем
+// проверяет и нормализует контакт согласно способу связи
+func validateIssue(obj interface{}, method, contact *string) error {
+	result := &ValidationError{}
+	if err := validate(obj); err != nil && !errors.As(err, &result) {
+		return err
+	}
+
+	_, methodFailed := result.Fields["preferredContactMethod"]
+	_, contactFailed := result.Fields["contactInfo"]
+	if !methodFailed && !contactFailed {
+		normalizeContact(method, contact, result)
+	}
+
+	if len(result.Fields) > 0 {
+		return result
+	}
+	return nil
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "обязательное поле"
+	case "oneof":
+		return "допустимые значения: " + fieldError.Param()
+	case "gte":
+		return "значение должно быть не меньше " + fieldError.Param()
+	default:
+		return "недопустимое значение"
+	}
+}
+
+// jsonFieldPath возвращает путь к полю в JSON по пути validator вида
+// Struct.Field[0].Nested. Встроенные структуры в пути не указываются
+func jsonFieldPath(obj interface{}, namespace string) string {
+	t := reflect.TypeOf(obj)
+	segments := strings.Split(namespace, ".")[1:]
+	path := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		field, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		if t.Kind() != reflect.Struct {
+			path = append(path, segment)
+			continue
+		}
+		f, ok := t.FieldByName(field)
+		if !ok {
+			path = append(path, segment)
+			continue
+		}
+		t = f.Type
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "" && f.Anonymous:
+			continue
+		case name == "":
+			name = field
+		}
+		path = append(path, name+index)
+	}
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,187 @@
+package sla
+
+import (
+	"math"
+	"time"
+
+	"calc_example/internal/config"
+	"calc_example/internal/model"
+)
+
+// Ограничение перебора дней при поиске рабочего времени
+const maxCalendarDays = 3660
+
+// Calendar считает рабочее время с учетом рабочих часов, выходных и праздников
+type Calendar struct {
+	location *time.Location
+	start    time.Duration
+	end      time.Duration
+	workDays map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+func NewCalendar(cfg config.SLAConfig) *Calendar {
+	calendar := &Calendar{
+		location: cfg.Location,
+		start:    cfg.WorkdayStart,
+		end:      cfg.WorkdayEnd,
+		workDays: make(map[time.Weekday]bool, len(cfg.WorkDays)),
+		holidays: make(map[string]bool, len(cfg.Holidays)),
+	}
+	if calendar.location == nil {
+		calendar.location = time.UTC
+	}
+	for _, day := range cfg.WorkDays {
+		calendar.workDays[day] = true
+	}
+	for _, day := range cfg.Holidays {
+		calendar.holidays[day] = true
+	}
+	return calendar
+}
+
+// WorkingTime возвращает продолжительность рабочего времени между from и to
+func (c *Calendar) WorkingTime(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	var total time.Duration
+	for day := c.dayOf(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !c.isWorkingDay(day) {
+			continue
+		}
+		start := latest(from, day.Add(c.start))
+		end := earliest(to, day.Add(c.end))
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Deadline возвращает момент, когда с from пройдет d рабочего времени
+func (c *Calendar) Deadline(from time.Time, d time.Duration) time.Time {
+	day := c.dayOf(from)
+	for i := 0; i < maxCalendarDays; i++ {
+		if c.isWorkingDay(day) {
+			start := latest(from.In(c.location), day.Add(c.start))
+			end := day.Add(c.end)
+			if end.After(start) {
+				available := end.Sub(start)
+				if d <= available {
+					return start.Add(d)
+				}
+				d -= available
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+func (c *Calendar) dayOf(t time.Time) time.Time {
+	t = t.In(c.location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.location)
+}
+
+func (c *Calendar) isWorkingDay(day time.Time) bool {
+	return c.workDays[day.Weekday()] && !c.holidays[day.Format("2006-01-02")]
+}
+
+// Policy оценивает состояние SLA заявки
+type Policy struct {
+	calendar     *Calendar
+	firstContact time.Duration
+	quote        time.Duration
+	warnBefore   time.Duration
+}
+
+func New(cfg config.SLAConfig) *Policy {
+	return &Policy{
+		calendar:     NewCalendar(cfg),
+		firstContact: cfg.FirstContactTarget,
+		quote:        cfg.QuoteTarget,
+		warnBefore:   cfg.WarnBefore,
+	}
+}
+
+func (p *Policy) Evaluate(issue *model.Issue, now time.Time) model.SLAState {
+	closed := issue.Status == model.StatusClosed || issue.Status == model.StatusLost
+	return model.SLAState{
+		FirstContact: p.metric(issue.CreatedAt, issue.FirstContactAt, p.firstContact, closed, now),
+		Quote:        p.metric(issue.CreatedAt, issue.QuotedAt, p.quote, closed, now),
+	}
+}
+
+// NeedsEscalation возвращает показатели, которые вот-вот будут нарушены
+// или уже нарушены и по которым эскалация еще не отправлялась
+func (p *Policy) NeedsEscalation(issue *model.Issue, now time.Time) []string {
+	state := p.Evaluate(issue, now)
+
+	var metrics []string
+	if !issue.FirstContactEscalated && isEscalated(state.FirstContact.Status) {
+		metrics = append(metrics, model.SLAMetricFirstContact)
+	}
+	if !issue.QuoteEscalated && isEscalated(state.Quote.Status) {
+		metrics = append(metrics, model.SLAMetricQuote)
+	}
+	return metrics
+}
+
+func (p *Policy) metric(start time.Time, completedAt *time.Time, target time.Duration, closed bool, now time.Time) model.SLAMetric {
+	metric := model.SLAMetric{
+		TargetMinutes: minutes(target),
+		Deadline:      p.calendar.Deadline(start, target),
+		CompletedAt:   completedAt,
+	}
+
+	switch {
+	case completedAt != nil:
+		elapsed := p.calendar.WorkingTime(start, *completedAt)
+		metric.ElapsedMinutes = minutes(elapsed)
+		if elapsed <= target {
+			metric.Status = model.SLAStatusMet
+		} else {
+			metric.Status = model.SLAStatusMissed
+		}
+	case closed:
+		// Заявка закрыта без выполнения показателя - отсчет остановлен
+		metric.Status = model.SLAStatusCancelled
+	default:
+		elapsed := p.calendar.WorkingTime(start, now)
+		metric.ElapsedMinutes = minutes(elapsed)
+		switch remaining := target - elapsed; {
+		case remaining < 0:
+			metric.Status = model.SLAStatusBreached
+		case remaining <= p.warnBefore:
+			metric.Status = model.SLAStatusAtRisk
+		default:
+			metric.Status = model.SLAStatusOnTrack
+		}
+	}
+
+	return metric
+}
+
+func isEscalated(status string) bool {
+	return status == model.SLAStatusAtRisk || status == model.SLAStatusBreached
+}
+
+func minutes(d time.Duration) float64 {
+	return math.Round(d.Minutes()*10) / 10
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,95 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"calc_example/internal/config"
+	"calc_example/internal/model"
+)
+
+func newTestPolicy() *Policy {
+	return New(config.SLAConfig{
+		FirstContactTarget: 15 * time.Minute,
+		QuoteTarget:        8 * time.Hour,
+		WarnBefore:         5 * time.Minute,
+		WorkdayStart:       9 * time.Hour,
+		WorkdayEnd:         18 * time.Hour,
+		WorkDays:           []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Holidays:           []string{"2025-08-04"},
+		Location:           time.UTC,
+	})
+}
+
+func TestCalendarWorkingTime(t *testing.T) {
+	calendar := newTestPolicy().calendar
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{
+			name: "внутри рабочего дня",
+			from: time.Date(2025, 8, 5, 10, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 8, 5, 10, 20, 0, 0, time.UTC),
+			want: 20 * time.Minute,
+		},
+		{
+			name: "заявка ночью отсчитывается с начала дня",
+			from: time.Date(2025, 8, 5, 2, 0, 0, 0, time.UTC),
+			to:   time.Date(2025, 8, 5, 9, 10, 0, 0, time.UTC),
+			want: 10 * time.Minute,
+		},
+		{
+			name: "выходные и праздник не считаются",
+			from: time.Date(2025, 8, 1, 17, 50, 0, 0, time.UTC), // пятница
+			to:   time.Date(2025, 8, 5, 9, 5, 0, 0, time.UTC),   // вторник после праздничного понедельника
+			want: 15 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.WorkingTime(tt.from, tt.to); got != tt.want {
+				t.Errorf("Ожидалось %v, получено %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCalendarDeadline(t *testing.T) {
+	calendar := newTestPolicy().calendar
+
+	from := time.Date(2025, 8, 1, 17, 50, 0, 0, time.UTC) // пятница
+	want := time.Date(2025, 8, 5, 9, 5, 0, 0, time.UTC)
+
+	if got := calendar.Deadline(from, 15*time.Minute); !got.Equal(want) {
+		t.Errorf("Ожидался срок %v, получен %v", want, got)
+	}
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := newTestPolicy()
+	created := time.Date(2025, 8, 5, 10, 0, 0, 0, time.UTC)
+	issue := &model.Issue{Status: model.StatusOpen, CreatedAt: created}
+
+	if state := policy.Evaluate(issue, created.Add(5*time.Minute)); state.FirstContact.Status != model.SLAStatusOnTrack {
+		t.Errorf("Ожидался статус %s, получен %s", model.SLAStatusOnTrack, state.FirstContact.Status)
+	}
+
+	if metrics := policy.NeedsEscalation(issue, created.Add(12*time.Minute)); len(metrics) != 1 || metrics[0] != model.SLAMetricFirstContact {
+		t.Errorf("Ожидалась эскалация первого контакта, получено %v", metrics)
+	}
+
+	issue.FirstContactEscalated = true
+	if metrics := policy.NeedsEscalation(issue, created.Add(20*time.Minute)); len(metrics) != 0 {
+		t.Errorf("Повторная эскалация не ожидалась, получено %v", metrics)
+	}
+
+	contacted := created.Add(20 * time.Minute)
+	issue.FirstContactAt = &contacted
+	if state := policy.Evaluate(issue, created.Add(time.Hour)); state.FirstContact.Status != model.SLAStatusMissed {
+		t.Errorf("Ожидался статус %s, получен %s", model.SLAStatusMissed, state.FirstContact.Status)
+	}
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"time"
 
 	"calc_example/internal/config"
 	"calc_example/internal/model"
@@ -22,7 +23,8 @@ func New(cfg config.DatabaseConfig) (*Database, error) {
 	switch cfg.Driver {
 	case "sqlite":
 		db, err = gorm.Open(sqlite.Open(cfg.DBName+".db"), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
+			Logger:  logger.Default.LogMode(logger.Info),
+			NowFunc: Now,
 		})
 	default:
 		return nil, fmt.Errorf("неподдерживаемый драйвер базы данных: %s", cfg.Driver)
@@ -32,14 +34,73 @@ func New(cfg config.DatabaseConfig) (*Database, error) {
 		return nil, fmt.Errorf("ошибка подключения к базе данных: %w", err)
 	}
 
-	// Автоматическая миграция моделей
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+
+	return &Database{db}, nil
+}
+
+// Now возвращает текущее время в UTC. Используется gorm для created_at
+// и updated_at, чтобы время в базе сравнивалось как текст (см. utcColumns)
+func Now() time.Time {
+	return time.Now().UTC()
+}
+
+// Migrate выполняет автоматическую миграцию моделей и заполняет справочники
+func Migrate(db *gorm.DB) error {
+	// Заявки, созданные до учета SLA, не эскалируются, иначе первая
+	// проверка отправит оповещения по всем старым открытым заявкам
+	legacySLA := db.Migrator().HasTable(&model.Issue{}) &&
+		!db.Migrator().HasColumn(&model.Issue{}, "FirstContactEscalated")
+
 	if err := db.AutoMigrate(
 		&model.Issue{},
+		&model.Tag{},
+		&model.Reminder{},
+		&model.AuditEntry{},
+		&model.Customer{},
+		&model.LossReason{},
+		&model.IssueLink{},
+		&model.Attachment{},
+		&model.LineItem{},
+		&model.ProductLink{},
 	); err != nil {
-		return nil, fmt.Errorf("ошибка миграции базы данных: %w", err)
+		return fmt.Errorf("ошибка миграции базы данных: %w", err)
 	}
 
-	return &Database{db}, nil
+	if legacySLA {
+		err := db.Unscoped().Model(&model.Issue{}).Where("1 = 1").
+			UpdateColumns(map[string]interface{}{"first_contact_escalated": true, "quote_escalated": true}).Error
+		if err != nil {
+			return fmt.Errorf("ошибка миграции SLA: %w", err)
+		}
+	}
+
+	// Предопределенные теги
+	for _, name := range model.PredefinedTags {
+		tag := model.Tag{Name: name, Predefined: true}
+		if err := db.Where(model.Tag{Name: name}).Attrs(tag).FirstOrCreate(&tag).Error; err != nil {
+			return fmt.Errorf("ошибка заполнения тегов: %w", err)
+		}
+	}
+
+	// Справочник причин проигрыша
+	for _, reason := range model.PredefinedLossReasons {
+		if err := db.Where(model.LossReason{Code: reason.Code}).Attrs(reason).FirstOrCreate(&reason).Error; err != nil {
+			return fmt.Errorf("ошибка заполнения причин проигрыша: %w", err)
+		}
+	}
+
+	if err := normalizeTimestamps(db); err != nil {
+		return fmt.Errorf("ошибка перевода времени в UTC: %w", err)
+	}
+
+	if err := migrateSearch(db); err != nil {
+		return fmt.Errorf("ошибка создания поискового индекса: %w", err)
+	}
+
+	return nil
 }
 
 func (db *Database) Close() error {
@@ -48,4 +109,4 @@ func (db *Database) Close() error {
 		return err
 	}
 	return sqlDB.Close()
-} 
\ No newline at end of file
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"testing"
+
+	"calc_example/internal/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB открывает SQLite в памяти и выполняет миграцию
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: Now,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Ошибка миграции: %v", err)
+	}
+	return db
+}
+
+func newTestIssue() model.Issue {
+	return model.Issue{
+		FullName:               "Иван Иванов",
+		ContactInfo:            "+79991234567",
+		PreferredContactMethod: model.ContactMethodPhone,
+		ProductDescription:     "Светильники",
+		ExpectedDeliveryDate:   "2024-12-01",
+		Status:                 model.StatusOpen,
+	}
+}
+
+func TestMigrateMarksLegacySLAEscalated(t *testing.T) {
+	db := newTestDB(t)
+
+	legacy := newTestIssue()
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// База до учета SLA
+	for _, column := range []string{"first_contact_escalated", "quote_escalated"} {
+		if err := db.Exec("ALTER TABLE issues DROP COLUMN " + column).Error; err != nil {
+			t.Fatalf("Ошибка подготовки базы: %v", err)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Ошибка повторной миграции: %v", err)
+	}
+	if err := db.First(&legacy, legacy.ID).Error; err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if !legacy.FirstContactEscalated || !legacy.QuoteEscalated {
+		t.Errorf("Старая заявка не должна эскалироваться, получено %v %v", legacy.FirstContactEscalated, legacy.QuoteEscalated)
+	}
+
+	issue := newTestIssue()
+	if err := db.Create(&issue).Error; err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Ошибка повторной миграции: %v", err)
+	}
+	if err := db.First(&issue, issue.ID).Error; err != nil {
+		t.Fatalf("Ошибка получения заявки: %v", err)
+	}
+	if issue.FirstContactEscalated || issue.QuoteEscalated {
+		t.Errorf("Новая заявка должна эскалироваться, получено %v %v", issue.FirstContactEscalated, issue.QuoteEscalated)
+	}
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SearchTable - полнотекстовый индекс заявок. Строка индекса имеет rowid
+// заявки и столбцы в порядке model.IssueSearchFields
+const SearchTable = "issue_search"
+
+// Модули полнотекстового поиска SQLite. FTS5 доступен при сборке
+// с тегом sqlite_fts5, иначе используется FTS4
+const (
+	SearchFTS5 = "fts5"
+	SearchFTS4 = "fts4"
+)
+
+const searchColumns = "full_name, contact_info, product_description, existing_product_links, notes"
+
+// searchValues возвращает значения столбцов индекса для заявки issue
+// (new в триггере или таблица issues). Буква «ё» заменяется на «е», к
+// контактам добавляются одни цифры, чтобы телефон находился без разделителей
+func searchValues(issue string) string {
+	text := func(expr string) string {
+		return fmt.Sprintf("REPLACE(REPLACE(COALESCE(%s, ''), 'ё', 'е'), 'Ё', 'Е')", expr)
+	}
+	digits := issue + ".contact_info"
+	for _, sep := range []string{"+", "-", " ", "(", ")", "."} {
+		digits = fmt.Sprintf("REPLACE(%s, '%s', '')", digits, sep)
+	}
+	notes := fmt.Sprintf("COALESCE(%s.loss_note, '') || ' ' || COALESCE((SELECT group_concat(note, ' ') FROM reminders WHERE reminders.issue_id = %s.id), '')", issue, issue)
+
+	return strings.Join([]string{
+		text(issue + ".full_name"),
+		text(issue+".contact_info") + " || ' ' || " + digits,
+		text(issue + ".product_description"),
+		text(issue + ".existing_product_links"),
+		text(notes),
+	}, ", ")
+}
+
+// migrateSearch создает полнотекстовый индекс заявок, заполняет его
+// существующими заявками и пересоздает триггеры, которые поддерживают
+// индекс при изменении заявок и напоминаний. Индекс с другим набором
+// столбцов удаляется и строится заново
+func migrateSearch(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := createSearchTable(tx); err != nil {
+			return err
+		}
+		return createSearchTriggers(tx)
+	})
+}
+
+// createSearchTable создает и заполняет индекс, если его нет или его
+// столбцы отличаются от searchColumns
+func createSearchTable(db *gorm.DB) error {
+	var columns []string
+	if err := db.Raw("SELECT name FROM pragma_table_info(?)", SearchTable).Scan(&columns).Error; err != nil {
+		return err
+	}
+	if strings.Join(columns, ", ") == searchColumns {
+		return nil
+	}
+	if len(columns) > 0 {
+		if err := db.Exec("DROP TABLE " + SearchTable).Error; err != nil {
+			return err
+		}
+	}
+
+	err := db.Exec(fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, tokenize = 'unicode61 remove_diacritics 2')", SearchTable, searchColumns)).Error
+	if err != nil && strings.Contains(err.Error(), "no such module") {
+		err = db.Exec(fmt.Sprintf(`CREATE VIRTUAL TABLE %s USING fts4(%s, tokenize=unicode61 "remove_diacritics=2")`, SearchTable, searchColumns)).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf("INSERT INTO %s(rowid, %s) SELECT issues.id, %s FROM issues", SearchTable, searchColumns, searchValues("issues"))).Error
+}
+
+// createSearchTriggers пересоздает триггеры индекса, чтобы они
+// соответствовали текущему набору столбцов
+func createSearchTriggers(db *gorm.DB) error {
+	insert := func(id, issue string) string {
+		return fmt.Sprintf("DELETE FROM %[1]s WHERE rowid = %[2]s; INSERT INTO %[1]s(rowid, %[3]s) SELECT %[4]s.id, %[5]s FROM issues %[4]s WHERE %[4]s.id = %[2]s;",
+			SearchTable, id, searchColumns, issue, searchValues(issue))
+	}
+	triggers := map[string]string{
+		"issue_search_insert":          "AFTER INSERT ON issues BEGIN " + insert("new.id", "i") + " END",
+		"issue_search_update":          "AFTER UPDATE ON issues BEGIN " + insert("new.id", "i") + " END",
+		"issue_search_delete":          fmt.Sprintf("AFTER DELETE ON issues BEGIN DELETE FROM %s WHERE rowid = old.id; END", SearchTable),
+		"issue_search_reminder_insert": "AFTER INSERT ON reminders BEGIN " + insert("new.issue_id", "i") + " END",
+		"issue_search_reminder_update": "AFTER UPDATE ON reminders BEGIN " + insert("old.issue_id", "i") + " " + insert("new.issue_id", "i") + " END",
+		"issue_search_reminder_delete": "AFTER DELETE ON reminders BEGIN " + insert("old.issue_id", "i") + " END",
+	}
+	for name, body := range triggers {
+		if err := db.Exec("DROP TRIGGER IF EXISTS " + name).Error; err != nil {
+			return err
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TRIGGER %s %s", name, body)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchEngine возвращает модуль, на котором построен индекс заявок
+func SearchEngine(db *gorm.DB) (string, error) {
+	var sql string
+	if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", SearchTable).Scan(&sql).Error; err != nil {
+		return "", err
+	}
+	if strings.Contains(strings.ToLower(sql), SearchFTS5) {
+		return SearchFTS5, nil
+	}
+	return SearchFTS4, nil
+}
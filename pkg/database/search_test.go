@@ -0,0 +1,50 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateSearchRebuildsIndex(t *testing.T) {
+	db := newTestDB(t)
+
+	issue := newTestIssue()
+	issue.LossNote = "Нашли дешевле"
+	if err := db.Create(&issue).Error; err != nil {
+		t.Fatalf("Ошибка создания заявки: %v", err)
+	}
+
+	// Индекс прежней версии со столбцом comments
+	for _, sql := range []string{
+		"DROP TABLE " + SearchTable,
+		"CREATE VIRTUAL TABLE " + SearchTable + " USING fts4(full_name, contact_info, product_description, existing_product_links, comments)",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatalf("Ошибка подготовки индекса: %v", err)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Ошибка повторной миграции: %v", err)
+	}
+
+	var columns []string
+	if err := db.Raw("SELECT name FROM pragma_table_info(?)", SearchTable).Scan(&columns).Error; err != nil {
+		t.Fatalf("Ошибка чтения столбцов индекса: %v", err)
+	}
+	if strings.Join(columns, ", ") != searchColumns {
+		t.Fatalf("Ожидались столбцы %s, получено %v", searchColumns, columns)
+	}
+
+	// Индекс заполнен заново, а пересозданные триггеры пишут в новые столбцы
+	if err := db.Model(&issue).Update("loss_note", "Сроки поставки").Error; err != nil {
+		t.Fatalf("Ошибка изменения заявки: %v", err)
+	}
+	var ids []uint
+	if err := db.Raw("SELECT rowid FROM "+SearchTable+" WHERE notes MATCH ?", "сроки").Scan(&ids).Error; err != nil {
+		t.Fatalf("Ошибка поиска: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != issue.ID {
+		t.Errorf("Ожидалась заявка %d, получено %v", issue.ID, ids)
+	}
+}
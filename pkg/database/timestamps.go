@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// utcColumns - столбцы времени, по которым база сравнивает значения.
+// SQLite хранит время текстом со смещением и сравнивает его как строку,
+// поэтому такие столбцы хранятся в UTC
+var utcColumns = []struct{ table, column string }{
+	{"reminders", "remind_at"},
+	{"issues", "created_at"},
+	{"issues", "updated_at"},
+	{"issues", "lost_at"},
+}
+
+// normalizeTimestamps переводит в UTC значения столбцов utcColumns,
+// сохраненные с другим смещением
+func normalizeTimestamps(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range utcColumns {
+			rows, err := tx.Table(c.table).
+				Select(fmt.Sprintf("id, %s", c.column)).
+				Where(fmt.Sprintf("%[1]s IS NOT NULL AND %[1]s NOT LIKE ?", c.column), "%+00:00").
+				Rows()
+			if err != nil {
+				return err
+			}
+
+			values := make(map[uint]time.Time)
+			for rows.Next() {
+				var id uint
+				var value time.Time
+				if err := rows.Scan(&id, &value); err != nil {
+					rows.Close()
+					return err
+				}
+				values[id] = value
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			for id, value := range values {
+				if err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", c.table, c.column), value.UTC(), id).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
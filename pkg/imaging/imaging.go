@@ -0,0 +1,97 @@
+// Package imaging готовит загруженные изображения к хранению: удаляет
+// метаданные и строит превью.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Декодеры поддерживаемых форматов
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+// ErrUnsupported возвращается для форматов, которые не обрабатываются
+var ErrUnsupported = errors.New("формат изображения не поддерживается")
+
+// MaxPixels - наибольший размер изображения, для которого строится превью.
+// Изображение распаковывается в память целиком, поэтому небольшой файл
+// с огромным разрешением может занять гигабайты
+const MaxPixels = 40_000_000
+
+// thumbnailQuality - качество JPEG превью
+const thumbnailQuality = 80
+
+// Image - изображение, подготовленное к сохранению
+type Image struct {
+	// Data - изображение без метаданных
+	Data []byte
+	// Thumbnail - превью в JPEG, nil для слишком больших изображений
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+// Process удаляет из изображения метаданные и строит превью, вписанное
+// в квадрат со стороной thumbSize
+func Process(data []byte, mimeType string, thumbSize int) (*Image, error) {
+	stripped, err := StripMetadata(data, mimeType)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+	result := &Image{Data: stripped, Width: config.Width, Height: config.Height}
+	if config.Width*config.Height > MaxPixels {
+		return result, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return nil, err
+	}
+	result.Thumbnail, err = Thumbnail(img, thumbSize)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Thumbnail уменьшает изображение до размера, вписанного в квадрат со
+// стороной size, и кодирует его в JPEG. Маленькие изображения не
+// увеличиваются, прозрачные области заливаются белым
+func Thumbnail(img image.Image, size int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), size)
+
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(thumb, thumb.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit возвращает размеры, вписанные в квадрат со стороной size
+// с сохранением пропорций
+func fit(width, height, size int) (int, int) {
+	if width <= size && height <= size {
+		return width, height
+	}
+	if width >= height {
+		return size, max(1, height*size/width)
+	}
+	return max(1, width*size/height), size
+}
@@ -0,0 +1,221 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// newTestImage возвращает изображение, левая половина которого красная,
+// а правая - синяя
+func newTestImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// exifWithOrientation собирает данные EXIF в формате TIFF с тегом Orientation
+// и строкой, имитирующей модель камеры
+func exifWithOrientation(orientation uint16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("MM\x00\x2a")
+	binary.Write(&buf, binary.BigEndian, uint32(8))
+	binary.Write(&buf, binary.BigEndian, uint16(1))
+	binary.Write(&buf, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&buf, binary.BigEndian, uint32(1))
+	binary.Write(&buf, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&buf, binary.BigEndian, uint32(0))
+	buf.WriteString("SecretCamera GPS 55.75,37.61")
+	return buf.Bytes()
+}
+
+func newTestJPEG(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("Ошибка кодирования JPEG: %v", err)
+	}
+
+	payload := append(append([]byte{}, exifHeader...), exifWithOrientation(orientation)...)
+	segment := []byte{0xff, jpegAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := append([]byte{}, encoded.Bytes()[:2]...)
+	data = append(data, segment...)
+	data = append(data, 0xff, jpegCOM, 0, 9)
+	data = append(data, "comment"...)
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := newTestJPEG(t, newTestImage(8, 4), 6)
+
+	stripped, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Ошибка удаления метаданных: %v", err)
+	}
+	if bytes.Contains(stripped, []byte("SecretCamera")) || bytes.Contains(stripped, []byte("comment")) {
+		t.Error("Метаданные не удалены")
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("Ошибка чтения результата: %v", err)
+	}
+	// Поворот на 90 градусов по часовой стрелке: красная половина наверху
+	if size := img.Bounds().Size(); size != image.Pt(4, 8) {
+		t.Fatalf("Ожидался размер 4x8, получено %v", size)
+	}
+	if r, _, b, _ := img.At(2, 1).RGBA(); r < b {
+		t.Error("Изображение повернуто неверно")
+	}
+}
+
+func TestStripJPEGWithoutRotation(t *testing.T) {
+	data := newTestJPEG(t, newTestImage(8, 4), 1)
+
+	stripped, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Ошибка удаления метаданных: %v", err)
+	}
+	// Без поворота изображение не перекодируется
+	if !bytes.Equal(stripped, newTestJPEGWithoutMetadata(t, data)) {
+		t.Error("Сжатые данные изображения изменены")
+	}
+
+	if _, err := StripMetadata(data[:20], "image/jpeg"); err == nil {
+		t.Error("Ожидалась ошибка для обрезанного файла")
+	}
+}
+
+// newTestJPEGWithoutMetadata вырезает из тестового JPEG добавленные сегменты
+func newTestJPEGWithoutMetadata(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	exifLength := int(binary.BigEndian.Uint16(data[4:]))
+	commentStart := 2 + 2 + exifLength
+	commentLength := int(binary.BigEndian.Uint16(data[commentStart+2:]))
+	return append(append([]byte{}, data[:2]...), data[commentStart+2+commentLength:]...)
+}
+
+func TestStripPNG(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, newTestImage(4, 2)); err != nil {
+		t.Fatalf("Ошибка кодирования PNG: %v", err)
+	}
+	raw := encoded.Bytes()
+	iend := len(raw) - 12
+
+	data := append([]byte{}, raw[:iend]...)
+	data = append(data, pngChunk("tEXt", []byte("Author\x00SecretCamera"))...)
+	data = append(data, pngChunk("eXIf", exifWithOrientation(8))...)
+	data = append(data, raw[iend:]...)
+
+	stripped, err := StripMetadata(data, "image/png")
+	if err != nil {
+		t.Fatalf("Ошибка удаления метаданных: %v", err)
+	}
+	if bytes.Contains(stripped, []byte("SecretCamera")) {
+		t.Error("Метаданные не удалены")
+	}
+
+	img, err := png.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("Ошибка чтения результата: %v", err)
+	}
+	// Поворот против часовой стрелки: синяя половина наверху
+	if size := img.Bounds().Size(); size != image.Pt(2, 4) {
+		t.Fatalf("Ожидался размер 2x4, получено %v", size)
+	}
+	if r, _, b, _ := img.At(0, 0).RGBA(); b < r {
+		t.Error("Изображение повернуто неверно")
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0, 1, 0, 0, 1, 0, 0}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range []struct {
+		fourCC string
+		data   []byte
+	}{
+		{"VP8X", vp8x},
+		{"VP8 ", []byte("image")},
+		{"EXIF", []byte("SecretCamera")},
+		{"XMP ", []byte("<xmp/>")},
+	} {
+		body.WriteString(chunk.fourCC)
+		binary.Write(&body, binary.LittleEndian, uint32(len(chunk.data)))
+		body.Write(chunk.data)
+		if len(chunk.data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(body.Len()))...)
+	data = append(data, body.Bytes()...)
+
+	stripped, err := StripMetadata(data, "image/webp")
+	if err != nil {
+		t.Fatalf("Ошибка удаления метаданных: %v", err)
+	}
+	if bytes.Contains(stripped, []byte("SecretCamera")) || bytes.Contains(stripped, []byte("<xmp/>")) {
+		t.Error("Метаданные не удалены")
+	}
+	if flags := stripped[20]; flags&(webpFlagEXIF|webpFlagXMP) != 0 {
+		t.Errorf("Флаги метаданных не сняты: %08b", flags)
+	}
+	if size := binary.LittleEndian.Uint32(stripped[4:]); int(size) != len(stripped)-8 {
+		t.Errorf("Неверный размер RIFF: %d", size)
+	}
+	if !bytes.Contains(stripped, []byte("VP8 \x05\x00\x00\x00image\x00")) {
+		t.Error("Данные изображения потеряны")
+	}
+}
+
+func TestProcess(t *testing.T) {
+	data := newTestJPEG(t, newTestImage(400, 100), 1)
+
+	result, err := Process(data, "image/jpeg", 200)
+	if err != nil {
+		t.Fatalf("Ошибка обработки изображения: %v", err)
+	}
+	if result.Width != 400 || result.Height != 100 {
+		t.Errorf("Ожидался размер 400x100, получено %dx%d", result.Width, result.Height)
+	}
+
+	thumb, err := jpeg.Decode(bytes.NewReader(result.Thumbnail))
+	if err != nil {
+		t.Fatalf("Ошибка чтения превью: %v", err)
+	}
+	if size := thumb.Bounds().Size(); size != image.Pt(200, 50) {
+		t.Errorf("Ожидался размер превью 200x50, получено %v", size)
+	}
+
+	if _, err := Process(data, "image/heic", 200); err != ErrUnsupported {
+		t.Errorf("Ожидалась ошибка неподдерживаемого формата, получено %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+)
+
+var errCorrupted = errors.New("поврежденное изображение")
+
+// jpegQuality - качество JPEG при повороте фотографии по данным EXIF
+const jpegQuality = 90
+
+// Маркеры JPEG
+const (
+	jpegSOI  = 0xd8
+	jpegEOI  = 0xd9
+	jpegSOS  = 0xda
+	jpegAPP0 = 0xe0
+	jpegAPP1 = 0xe1
+	jpegAPP2 = 0xe2
+	jpegAPPE = 0xee
+	jpegAPPF = 0xef
+	jpegCOM  = 0xfe
+)
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader   = []byte("Exif\x00\x00")
+)
+
+// pngMetadataChunks - блоки PNG с метаданными
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// Флаги заголовка VP8X, отмечающие метаданные WebP
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+// StripMetadata удаляет из изображения метаданные: EXIF с геопозицией,
+// моделью камеры и временем съемки, XMP, IPTC и текстовые комментарии.
+// Поворот фотографии, записанный в EXIF JPEG и PNG, применяется к самому
+// изображению, чтобы после удаления EXIF она не оказалась на боку
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(mimeType, "image/jpeg"):
+		return stripJPEG(data)
+	case strings.HasPrefix(mimeType, "image/png"):
+		return stripPNG(data)
+	case strings.HasPrefix(mimeType, "image/webp"):
+		return stripWebP(data)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// stripJPEG удаляет сегменты APP1 (EXIF, XMP), APP3-APP13, APP15
+// и комментарии. JFIF, цветовой профиль ICC (APP2) и сегмент Adobe
+// (APP14) влияют на отображение и сохраняются
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != jpegSOI {
+		return nil, errCorrupted
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	orientation := 1
+
+	pos := 2
+	for {
+		if pos+2 > len(data) || data[pos] != 0xff {
+			return nil, errCorrupted
+		}
+		// Перед маркером допускаются заполняющие байты 0xff
+		for pos+1 < len(data) && data[pos+1] == 0xff {
+			pos++
+		}
+		if pos+2 > len(data) {
+			return nil, errCorrupted
+		}
+		marker := data[pos+1]
+
+		// Дальше идут сжатые данные, метаданные в них не встречаются
+		if marker == jpegSOS || marker == jpegEOI {
+			out.Write(data[pos:])
+			break
+		}
+		// Маркеры без данных
+		if marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			out.Write(data[pos : pos+2])
+			pos += 2
+			continue
+		}
+
+		if pos+4 > len(data) {
+			return nil, errCorrupted
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) || end < pos+4 {
+			return nil, errCorrupted
+		}
+		segment := data[pos:end]
+		pos = end
+
+		if marker == jpegAPP1 && bytes.HasPrefix(segment[4:], exifHeader) {
+			orientation = exifOrientation(segment[4+len(exifHeader):])
+		}
+		if isJPEGMetadata(marker) {
+			continue
+		}
+		out.Write(segment)
+	}
+
+	if orientation == 1 {
+		return out.Bytes(), nil
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isJPEGMetadata(marker byte) bool {
+	switch {
+	case marker == jpegCOM:
+		return true
+	case marker < jpegAPP0 || marker > jpegAPPF:
+		return false
+	default:
+		return marker != jpegAPP0 && marker != jpegAPP2 && marker != jpegAPPE
+	}
+}
+
+// stripPNG удаляет блоки EXIF, текстовые блоки и время изменения
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errCorrupted
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	orientation := 1
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errCorrupted
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) || end < pos {
+			return nil, errCorrupted
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunk := data[pos:end]
+		pos = end
+
+		if chunkType == "eXIf" {
+			orientation = exifOrientation(chunk[8 : 8+length])
+		}
+		if pngMetadataChunks[chunkType] {
+			continue
+		}
+		out.Write(chunk)
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	if orientation == 1 {
+		return out.Bytes(), nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, orient(img, orientation)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// stripWebP удаляет блоки EXIF и XMP и снимает их флаги в заголовке VP8X.
+// Кодировщика WebP в стандартной библиотеке нет, поэтому поворот из EXIF
+// к изображению не применяется
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errCorrupted
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errCorrupted
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		// Блоки выравниваются до четной длины
+		end := pos + 8 + size + size%2
+		if size < 0 || end > len(data) || end < pos {
+			return nil, errCorrupted
+		}
+		fourCC := string(data[pos : pos+4])
+		chunk := data[pos:end]
+		pos = end
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			if size > 0 {
+				chunk = append([]byte{}, chunk...)
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+		}
+		out.Write(chunk)
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, nil
+}
+
+// exifOrientation возвращает значение тега Orientation из данных EXIF
+// в формате TIFF или 1, если тега нет
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient поворачивает и отражает изображение так, как требует значение
+// тега Orientation
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if orientation >= 5 {
+		width, height = height, width
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	w, h := bounds.Dx(), bounds.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
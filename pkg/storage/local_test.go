@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Ошибка создания хранилища: %v", err)
+	}
+
+	key := NewKey()
+	if err := local.Put(key, []byte("содержимое"), "text/plain"); err != nil {
+		t.Fatalf("Ошибка сохранения: %v", err)
+	}
+
+	file, err := local.Get(key)
+	if err != nil {
+		t.Fatalf("Ошибка чтения: %v", err)
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil || string(data) != "содержимое" {
+		t.Errorf("Ожидалось сохраненное содержимое, получено %q (%v)", data, err)
+	}
+
+	if err := local.Delete(key); err != nil {
+		t.Fatalf("Ошибка удаления: %v", err)
+	}
+	if _, err := local.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Ожидалась ошибка отсутствия объекта, получено %v", err)
+	}
+
+	if err := local.Put("../outside", []byte("x"), "text/plain"); err == nil {
+		t.Errorf("Ключ не должен выходить за пределы каталога")
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"calc_example/internal/config"
+)
+
+// ErrNotFound возвращается, если объекта с указанным ключом нет в хранилище
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+// Storage хранит содержимое вложений по ключу
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Presigner реализуют хранилища, которые умеют выдавать временные ссылки
+// на скачивание, чтобы файл отдавался клиенту напрямую, минуя сервер
+type Presigner interface {
+	PresignedURL(key, fileName string, expires time.Duration) (string, error)
+}
+
+// New создает хранилище, выбранное в конфигурации
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.LocalPath)
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("неподдерживаемое хранилище файлов: %s", cfg.Driver)
+	}
+}
+
+// NewKey возвращает уникальный ключ для нового объекта, разложенный
+// по каталогам месяца загрузки
+func NewKey() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return time.Now().Format("2006/01") + "/" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client отправляет сообщения через сервис Telegram бота
+type Client struct {
+	url        string
+	httpClient *http.Client
+}
+
+func New(url string) *Client {
+	return &Client{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SendMessage отправляет HTML-сообщение в чат менеджеров
+func (c *Client) SendMessage(text string) error {
+	data := map[string]string{"text": text}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("_ошибка при преобразовании в JSON: %v", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", c.url+"/send-message", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("__ошибка при создании запроса: %v", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("___ошибка при выполнении запроса: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body) // Читаем тело ответа для диагностики
+		return fmt.Errorf("неуспешный статус ответа: %s, тело: %s", resp.Status, string(body))
+	}
+
+	return nil
+}